@@ -0,0 +1,160 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// InputGradients calculates the gradient of a single network output with respect
+// to each of the inputs.  The magnitude of each value is a measure of how
+// sensitive the output is to a small change in that input, which is often
+// displayed as a saliency map.
+func (n *Network) InputGradients(inputs []float64, output int) ([]float64, error) {
+	if output < 0 || output >= n.OutputSize() {
+		return nil, fmt.Errorf("Unable to attribute output %d of a network with %d outputs", output, n.OutputSize())
+	}
+
+	outputGrad := make([]float64, n.OutputSize())
+	outputGrad[output] = 1.0
+
+	grads, err := n.Backward(inputs, outputGrad)
+	if err != nil {
+		return nil, err
+	}
+	return grads.Inputs, nil
+}
+
+// GradientTimesInput multiplies the input gradients for the given output by the
+// inputs themselves.  Unlike the raw gradient, it takes into account how large
+// each input actually is.
+func (n *Network) GradientTimesInput(inputs []float64, output int) ([]float64, error) {
+	grads, err := n.InputGradients(inputs, output)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range grads {
+		grads[idx] *= inputs[idx]
+	}
+	return grads, nil
+}
+
+// IntegratedGradients attributes the given output to the inputs by averaging the
+// input gradients along a straight line from the baseline to the inputs and
+// multiplying by the distance between the inputs and the baseline.  The scores
+// approximately sum to the difference between the network output for the inputs
+// and for the baseline.  A nil baseline is treated as all zeros.  More steps
+// give a better approximation at the cost of more backward passes.
+func (n *Network) IntegratedGradients(inputs, baseline []float64, output, steps int) ([]float64, error) {
+	if baseline == nil {
+		baseline = make([]float64, len(inputs))
+	}
+
+	if len(baseline) != len(inputs) {
+		return nil, fmt.Errorf("Baseline length %d does not match input length %d", len(baseline), len(inputs))
+	}
+
+	if steps < 1 {
+		return nil, fmt.Errorf("Integrated gradients requires at least one step, not: %d", steps)
+	}
+
+	result := make([]float64, len(inputs))
+	point := make([]float64, len(inputs))
+	for step := 1; step <= steps; step++ {
+		fraction := (float64(step) - 0.5) / float64(steps)
+		for idx := range inputs {
+			point[idx] = baseline[idx] + fraction*(inputs[idx]-baseline[idx])
+		}
+
+		grads, err := n.InputGradients(point, output)
+		if err != nil {
+			return nil, err
+		}
+
+		for idx := range grads {
+			result[idx] += grads[idx]
+		}
+	}
+
+	for idx := range result {
+		result[idx] = result[idx] / float64(steps) * (inputs[idx] - baseline[idx])
+	}
+	return result, nil
+}
+
+// PermutationImportance measures how much the network relies on each input by
+// shuffling that input's column across the data set and measuring the increase
+// in the combined mean squared error.  The shuffle is repeated for the given
+// number of rounds and the increases are averaged.  An input with a score near
+// zero can be shuffled without hurting the network.  The training data is not
+// modified.
+func PermutationImportance(net Network, td TrainingData, rounds int) ([]float64, error) {
+	if len(td) == 0 {
+		return nil, fmt.Errorf("Unable to calculate permutation importance without data")
+	}
+
+	if rounds < 1 {
+		return nil, fmt.Errorf("Permutation importance requires at least one round, not: %d", rounds)
+	}
+
+	baseErrors, err := Evaluate(net, td)
+	if err != nil {
+		return nil, err
+	}
+	baseline := baseErrors.Average().Combine()
+
+	permuted := make(TrainingData, len(td))
+	for idx, datum := range td {
+		permuted[idx] = TrainingDatum{Inputs: make([]float64, len(datum.Inputs)), Expected: datum.Expected}
+		copy(permuted[idx].Inputs, datum.Inputs)
+	}
+
+	result := make([]float64, len(td[0].Inputs))
+	for col := range result {
+		for round := 0; round < rounds; round++ {
+			for idx, src := range rand.Perm(len(td)) {
+				permuted[idx].Inputs[col] = td[src].Inputs[col]
+			}
+
+			errors, err := Evaluate(net, permuted)
+			if err != nil {
+				return nil, err
+			}
+			result[col] += errors.Average().Combine() - baseline
+		}
+
+		for idx := range permuted {
+			permuted[idx].Inputs[col] = td[idx].Inputs[col]
+		}
+		result[col] = result[col] / float64(rounds)
+	}
+	return result, nil
+}
@@ -0,0 +1,119 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import "testing"
+
+func TestNetwork_InputGradients(t *testing.T) {
+	net := MakeNetwork(2, 1)
+	net.Layers[0].Weights[0][0] = 2.0
+	net.Layers[0].Weights[0][1] = 0.0
+
+	grads, err := net.InputGradients([]float64{0.0, 1.0}, 0)
+	if err != nil {
+		t.Errorf("Failed to calculate input gradients: %v", err)
+	}
+
+	if outOfBoundsCheck(0.5, grads[0], 0.001) || outOfBoundsCheck(0.0, grads[1], 0.001) {
+		t.Errorf("Expected gradients of [0.5 0.0] but got %v", grads)
+	}
+
+	if _, err := net.InputGradients([]float64{0.0, 1.0}, 1); err == nil {
+		t.Error("Expected an error for an output that does not exist")
+	}
+}
+
+func TestNetwork_GradientTimesInput(t *testing.T) {
+	net := MakeNetwork(2, 1)
+	net.Layers[0].Weights[0][0] = 2.0
+	net.Layers[0].Weights[0][1] = 2.0
+
+	scores, _ := net.GradientTimesInput([]float64{0.0, 1.0}, 0)
+	if !outOfBoundsCheck(0.0, scores[1], 0.001) || outOfBoundsCheck(0.0, scores[0], 0.001) {
+		t.Errorf("Expected only the non-zero input to be scored but got %v", scores)
+	}
+}
+
+func TestNetwork_IntegratedGradients(t *testing.T) {
+	net := MakeNetwork(3, 4, 2)
+	net.Randomize()
+	inputs := []float64{0.9, 0.1, 0.5}
+	baseline := []float64{0.1, 0.1, 0.1}
+
+	scores, err := net.IntegratedGradients(inputs, baseline, 1, 50)
+	if err != nil {
+		t.Errorf("Failed to calculate integrated gradients: %v", err)
+	}
+
+	high, _ := net.Process(inputs)
+	low, _ := net.Process(baseline)
+
+	sum := scores[0] + scores[1] + scores[2]
+	if outOfBoundsCheck(high[1]-low[1], sum, 0.0001) {
+		t.Errorf("Expected scores to sum to %0.6f but got %0.6f", high[1]-low[1], sum)
+	}
+
+	if outOfBoundsCheck(0.0, scores[1], 0.000001) {
+		t.Errorf("An input equal to its baseline should score 0.0 but got %0.6f", scores[1])
+	}
+
+	if _, err := net.IntegratedGradients(inputs, []float64{0.0}, 0, 50); err == nil {
+		t.Error("Expected an error for a baseline of the wrong size")
+	}
+}
+
+func TestPermutationImportance(t *testing.T) {
+	net := MakeNetwork(2, 1)
+	net.Layers[0].Weights[0][0] = 8.0
+	net.Layers[0].Weights[0][2] = -4.0
+
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{0.0, 0.3}, Expected: []float64{0.1}},
+		TrainingDatum{Inputs: []float64{1.0, 0.7}, Expected: []float64{0.9}},
+		TrainingDatum{Inputs: []float64{0.0, 0.2}, Expected: []float64{0.1}},
+		TrainingDatum{Inputs: []float64{1.0, 0.9}, Expected: []float64{0.9}},
+	}
+
+	scores, err := PermutationImportance(net, td, 20)
+	if err != nil {
+		t.Errorf("Failed to calculate permutation importance: %v", err)
+	}
+
+	if scores[0] <= 0.0 {
+		t.Errorf("Expected the first input to be important but got %0.4f", scores[0])
+	}
+
+	if outOfBoundsCheck(0.0, scores[1], 0.000001) {
+		t.Errorf("Expected the unused input to score 0.0 but got %0.4f", scores[1])
+	}
+
+	if td[1].Inputs[0] != 1.0 || td[2].Inputs[0] != 0.0 {
+		t.Errorf("The training data should not have been modified")
+	}
+}
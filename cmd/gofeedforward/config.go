@@ -0,0 +1,429 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DarcInc/gofeedforward"
+	"sigs.k8s.io/yaml"
+)
+
+// trainConfig describes a training run.  It is read from YAML or, since JSON
+// is a subset of YAML, from JSON.
+type trainConfig struct {
+	Network networkConfig `json:"network"`
+	Trainer trainerConfig `json:"trainer"`
+	Data    dataConfig    `json:"data"`
+	Output  outputConfig  `json:"output"`
+}
+
+// networkConfig gives the layer sizes passed to MakeNetwork, starting with the
+// number of inputs and ending with the number of outputs.  The network only
+// supports the sigmoid activation, which is the default.
+type networkConfig struct {
+	Layers     []int  `json:"layers"`
+	Activation string `json:"activation"`
+}
+
+// trainerConfig holds the Trainer settings and the simple stopping criteria.
+// Progress is logged every LogEvery iterations.
+type trainerConfig struct {
+	Alpha         float64   `json:"alpha"`
+	Batch         bool      `json:"batch"`
+	ShuffleRounds int       `json:"shuffle_rounds"`
+	OutputWeights []float64 `json:"output_weights"`
+	MaxIterations int       `json:"max_iterations"`
+	MinError      float64   `json:"min_error"`
+	LogEvery      int       `json:"log_every"`
+}
+
+// fieldConfig names an input column and how it is encoded: "number", the
+// default, "onehot", "ordinal" or "hash" with the given number of buckets.
+type fieldConfig struct {
+	Column   string `json:"column"`
+	Encoding string `json:"encoding"`
+	Buckets  int    `json:"buckets"`
+}
+
+// targetConfig names the columns holding the expected values.  With the
+// "number" encoding, the default, each column is an expected value and Scale
+// maps them between 0.1 and 0.9 so the sigmoid outputs can reach them.  With
+// "onehot" there must be a single column of class names, and the model is
+// saved with a best of classifier for those classes.
+type targetConfig struct {
+	Columns  []string `json:"columns"`
+	Encoding string   `json:"encoding"`
+	Scale    bool     `json:"scale"`
+}
+
+// dataConfig gives the data files and the role of their columns.  Each file
+// must have a header row.  Columns that are not an input, target or weight are
+// ignored.  If there is no validation file, Split is the fraction of the
+// shuffled training file used for training, with the rest used for
+// validation.  The pipeline is only fit to the training part, so every
+// category must appear in it.  Impute is an ImputeStrategy for missing inputs, with
+// ImputeValue as the value for the constant strategy, and Scale is a
+// ScaleMethod for the inputs.
+type dataConfig struct {
+	Train       string        `json:"train"`
+	Validation  string        `json:"validation"`
+	Split       float64       `json:"split"`
+	Comma       string        `json:"comma"`
+	Inputs      []fieldConfig `json:"inputs"`
+	Target      targetConfig  `json:"target"`
+	Weight      string        `json:"weight"`
+	Impute      string        `json:"impute"`
+	ImputeValue float64       `json:"impute_value"`
+	Scale       string        `json:"scale"`
+}
+
+// outputConfig names the files written after training.  Model is the saved
+// pipeline, Report is the metrics report and History is the training history
+// as CSV.  Only the model is required.
+type outputConfig struct {
+	Model   string `json:"model"`
+	Report  string `json:"report"`
+	History string `json:"history"`
+}
+
+// loadConfig reads a training configuration, fills in the defaults and checks
+// it.  Relative paths in the configuration are relative to the configuration
+// file.
+func loadConfig(path string) (trainConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return trainConfig{}, err
+	}
+
+	config := trainConfig{
+		Network: networkConfig{Activation: "sigmoid"},
+		Trainer: trainerConfig{Alpha: 0.1, MaxIterations: 1000, LogEvery: 100},
+		Output:  outputConfig{Model: "model.json"},
+	}
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return trainConfig{}, fmt.Errorf("%s: %v", path, err)
+	}
+
+	if err := config.validate(); err != nil {
+		return trainConfig{}, fmt.Errorf("%s: %v", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for _, p := range []*string{&config.Data.Train, &config.Data.Validation,
+		&config.Output.Model, &config.Output.Report, &config.Output.History} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+	return config, nil
+}
+
+func (c trainConfig) validate() error {
+	if c.Network.Activation != "sigmoid" {
+		return fmt.Errorf("unsupported activation %q, only sigmoid is available", c.Network.Activation)
+	}
+
+	if len(c.Network.Layers) < 2 {
+		return fmt.Errorf("network needs at least an input and an output layer size")
+	}
+
+	if c.Data.Train == "" {
+		return fmt.Errorf("no training data file")
+	}
+
+	if c.Data.Split < 0.0 || c.Data.Split > 1.0 {
+		return fmt.Errorf("split must be between 0.0 and 1.0, not %v", c.Data.Split)
+	}
+
+	if len(c.Data.Inputs) == 0 {
+		return fmt.Errorf("no input columns")
+	}
+
+	if utf8.RuneCountInString(c.Data.Comma) > 1 {
+		return fmt.Errorf("comma must be a single character, not %q", c.Data.Comma)
+	}
+
+	switch c.Data.Target.Encoding {
+	case "", "number":
+		if len(c.Data.Target.Columns) == 0 {
+			return fmt.Errorf("no target columns")
+		}
+	case "onehot":
+		if len(c.Data.Target.Columns) != 1 {
+			return fmt.Errorf("a onehot target needs exactly one column, not %d", len(c.Data.Target.Columns))
+		}
+	default:
+		return fmt.Errorf("unknown target encoding %q", c.Data.Target.Encoding)
+	}
+
+	if c.Output.Model == "" {
+		return fmt.Errorf("no model output file")
+	}
+	return nil
+}
+
+// loader returns the CSVLoader for the data files.  The input columns are the
+// loader's inputs and the target columns, followed by the weight column if
+// there is one, are its expected values.
+func (c dataConfig) loader() gofeedforward.CSVLoader {
+	loader := gofeedforward.CSVLoader{Header: true}
+	if c.Comma != "" {
+		loader.Comma, _ = utf8.DecodeRuneInString(c.Comma)
+	}
+
+	for _, input := range c.Inputs {
+		loader.Inputs = append(loader.Inputs, gofeedforward.ColumnNamed(input.Column))
+	}
+
+	for _, name := range c.Target.Columns {
+		loader.Expected = append(loader.Expected, gofeedforward.ColumnNamed(name))
+	}
+
+	if c.Weight != "" {
+		loader.Expected = append(loader.Expected, gofeedforward.ColumnNamed(c.Weight))
+	}
+	return loader
+}
+
+// read reads the records of a CSV file with a header row and at least one row
+// of data.
+func (c dataConfig) read(path string) ([]string, []gofeedforward.CSVRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	header, records, err := c.loader().LoadRecords(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("%s: expected a header row and at least one row of data", path)
+	}
+	return header, records, nil
+}
+
+// inputValues returns the values of the input column at the index.
+func inputValues(records []gofeedforward.CSVRecord, idx int) []string {
+	values := make([]string, len(records))
+	for row, record := range records {
+		values[row] = record.Inputs[idx]
+	}
+	return values
+}
+
+// fitPipeline creates a pipeline, without a network, from the training records.
+// The categorical encoders, imputer, input scaler and output scaler are all
+// fit to the training records.
+func (c dataConfig) fitPipeline(records []gofeedforward.CSVRecord) (gofeedforward.Pipeline, error) {
+	pipeline := gofeedforward.Pipeline{}
+	for idx, input := range c.Inputs {
+		field := gofeedforward.Field{Name: input.Column}
+		switch input.Encoding {
+		case "", "number":
+		case "onehot":
+			encoder := gofeedforward.FitOneHotEncoder(inputValues(records, idx), 1.0, 0.0)
+			field.OneHot = &encoder
+		case "ordinal":
+			encoder := gofeedforward.FitOrdinalEncoder(inputValues(records, idx))
+			field.Ordinal = &encoder
+		case "hash":
+			if input.Buckets < 1 {
+				return pipeline, fmt.Errorf("column %q: hash encoding needs buckets", input.Column)
+			}
+			encoder := gofeedforward.MakeHashEncoder(input.Buckets, 1.0, 0.0)
+			field.Hash = &encoder
+		default:
+			return pipeline, fmt.Errorf("column %q: unknown encoding %q", input.Column, input.Encoding)
+		}
+		pipeline.Fields = append(pipeline.Fields, field)
+	}
+
+	if c.Impute != "" {
+		encoded := [][]float64{}
+		for _, record := range records {
+			inputs, err := encode(pipeline, record)
+			if err != nil {
+				return pipeline, err
+			}
+			encoded = append(encoded, inputs)
+		}
+
+		imputer, err := fitImputer(gofeedforward.ImputeStrategy(c.Impute), encoded, c.ImputeValue)
+		if err != nil {
+			return pipeline, err
+		}
+		pipeline.Imputers = append(pipeline.Imputers, imputer)
+	}
+
+	if c.Target.Encoding == "onehot" {
+		values := make([]string, len(records))
+		for row, record := range records {
+			values[row] = record.Expected[0]
+		}
+		encoder := gofeedforward.FitOneHotEncoder(values, 0.9, 0.1)
+		pipeline.Classifier = &gofeedforward.ClassifierSpec{Type: "best", Classes: encoder.Categories}
+	}
+
+	if c.Scale != "" {
+		data, err := c.prepare(pipeline, records)
+		if err != nil {
+			return pipeline, err
+		}
+
+		scaler, err := fitScaler(gofeedforward.ScaleMethod(c.Scale), data.InputValues())
+		if err != nil {
+			return pipeline, err
+		}
+		pipeline.Scalers = append(pipeline.Scalers, scaler)
+	}
+
+	if c.Target.Scale && c.Target.Encoding != "onehot" {
+		data, err := c.prepare(pipeline, records)
+		if err != nil {
+			return pipeline, err
+		}
+
+		scaler, err := gofeedforward.FitMinMaxScaler(data.ExpectedValues(), 0.1, 0.9)
+		if err != nil {
+			return pipeline, err
+		}
+		pipeline.OutputScaler = &scaler
+	}
+	return pipeline, nil
+}
+
+func fitImputer(strategy gofeedforward.ImputeStrategy, values [][]float64, constant float64) (gofeedforward.Imputer, error) {
+	switch strategy {
+	case gofeedforward.MeanImputation:
+		return gofeedforward.FitMeanImputer(values, false)
+	case gofeedforward.MedianImputation:
+		return gofeedforward.FitMedianImputer(values, false)
+	case gofeedforward.MostFrequentImputation:
+		return gofeedforward.FitMostFrequentImputer(values, false)
+	case gofeedforward.ConstantImputation:
+		return gofeedforward.FitConstantImputer(values, constant, false)
+	}
+	return gofeedforward.Imputer{}, fmt.Errorf("unknown impute strategy %q", strategy)
+}
+
+func fitScaler(method gofeedforward.ScaleMethod, values [][]float64) (gofeedforward.Scaler, error) {
+	switch method {
+	case gofeedforward.MinMaxScaling:
+		return gofeedforward.FitMinMaxScaler(values, 0.0, 1.0)
+	case gofeedforward.ZScoreScaling:
+		return gofeedforward.FitZScoreScaler(values)
+	case gofeedforward.RobustScaling:
+		return gofeedforward.FitRobustScaler(values)
+	case gofeedforward.LogScaling:
+		return gofeedforward.FitLogScaler(values)
+	}
+	return gofeedforward.Scaler{}, fmt.Errorf("unknown scale method %q", method)
+}
+
+// encode returns the encoded inputs of a record before any imputing or
+// scaling, with missing numbers as NaN.
+func encode(pipeline gofeedforward.Pipeline, record gofeedforward.CSVRecord) ([]float64, error) {
+	inputs := []float64{}
+	for idx, field := range pipeline.Fields {
+		values, err := field.Encode(record.Inputs[idx])
+		if err != nil {
+			return nil, fmt.Errorf("line %d, column %q: %v", record.Line, field.Name, err)
+		}
+		inputs = append(inputs, values...)
+	}
+	return inputs, nil
+}
+
+// expected returns the expected values of a record, encoded and scaled the way
+// the pipeline's outputs are.  If the pipeline has a classifier, the target is
+// a single column of class names.
+func (c dataConfig) expected(pipeline gofeedforward.Pipeline, record gofeedforward.CSVRecord) ([]float64, error) {
+	values := []float64{}
+	for idx, name := range c.Target.Columns {
+		field := record.Expected[idx]
+		if pipeline.Classifier != nil {
+			var encoder gofeedforward.Encoder = gofeedforward.OneHotEncoder{Categories: pipeline.Classifier.Classes, On: 0.9, Off: 0.1}
+			if pipeline.Classifier.Type == "ordinal" {
+				encoder = gofeedforward.OrdinalEncoder{Categories: pipeline.Classifier.Classes}
+			}
+
+			encoded, err := encoder.Encode(field)
+			if err != nil {
+				return nil, fmt.Errorf("column %q: %v", name, err)
+			}
+			values = append(values, encoded...)
+			continue
+		}
+
+		value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %q is not a number", name, field)
+		}
+		values = append(values, value)
+	}
+
+	if pipeline.OutputScaler != nil {
+		return pipeline.OutputScaler.Transform(values)
+	}
+	return values, nil
+}
+
+// prepare turns records into training data using the pipeline.
+func (c dataConfig) prepare(pipeline gofeedforward.Pipeline, records []gofeedforward.CSVRecord) (gofeedforward.TrainingData, error) {
+	data := gofeedforward.TrainingData{}
+	for _, record := range records {
+		var err error
+		datum := gofeedforward.TrainingDatum{}
+		if datum.Inputs, err = pipeline.Inputs(record.Inputs); err != nil {
+			return nil, fmt.Errorf("line %d: %v", record.Line, err)
+		}
+
+		if datum.Expected, err = c.expected(pipeline, record); err != nil {
+			return nil, fmt.Errorf("line %d: %v", record.Line, err)
+		}
+
+		if c.Weight != "" {
+			weight := record.Expected[len(c.Target.Columns)]
+			if datum.Weight, err = strconv.ParseFloat(strings.TrimSpace(weight), 64); err != nil {
+				return nil, fmt.Errorf("line %d: weight %q is not a number", record.Line, weight)
+			}
+		}
+		data = append(data, datum)
+	}
+	return data, nil
+}
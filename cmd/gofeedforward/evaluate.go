@@ -0,0 +1,117 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+func evaluate(args []string) error {
+	flags := flag.NewFlagSet("evaluate", flag.ExitOnError)
+	path := flags.String("model", "model.json", "saved pipeline or network to evaluate")
+	dataPath := flags.String("data", "", "CSV file, with a header row, to evaluate the model against")
+	inputs := flags.String("inputs", "", "comma separated input columns if the model has no fields")
+	targets := flags.String("target", "", "comma separated target columns; a single column of class names if the model has a classifier")
+	comma := flags.String("comma", "", "field delimiter if not a comma")
+	classes := flags.String("classes", "", "comma separated class names if the model has no classifier")
+	threshold := flags.Float64("threshold", 0.0, "use a threshold classifier with this threshold instead of best of")
+	reportPath := flags.String("report", "", "also write the report to this file")
+	flags.Parse(args)
+
+	if *dataPath == "" || *targets == "" {
+		return fmt.Errorf("both -data and -target are required")
+	}
+
+	pipeline, err := loadModel(*path)
+	if err != nil {
+		return err
+	}
+
+	classifier, err := classifierFor(pipeline, *classes, *threshold)
+	if err != nil {
+		return err
+	}
+
+	if pipeline, err = withInputs(pipeline, *inputs); err != nil {
+		return err
+	}
+
+	config := dataFor(pipeline, *targets, *comma)
+	_, records, err := config.read(*dataPath)
+	if err != nil {
+		return err
+	}
+
+	data, err := config.prepare(pipeline, records)
+	if err != nil {
+		return fmt.Errorf("%s: %v", *dataPath, err)
+	}
+
+	allErrors, err := gofeedforward.Evaluate(pipeline.Network, data)
+	if err != nil {
+		return err
+	}
+
+	var report bytes.Buffer
+	mse := allErrors.Average()
+	fmt.Fprintf(&report, "mean squared error %s (combined %0.6f)\n", formatValues(mse), mse.Combine())
+
+	if classifier != nil {
+		rate, err := gofeedforward.ClassificationError(pipeline.Network, data, classifier)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&report, "classification error %0.4f\n", rate)
+	}
+
+	report.WriteString("\n")
+	if err := writeReport(&report, *dataPath, pipeline, classifier, data); err != nil {
+		return err
+	}
+
+	os.Stdout.Write(report.Bytes())
+	if *reportPath != "" {
+		return os.WriteFile(*reportPath, report.Bytes(), 0o644)
+	}
+	return nil
+}
+
+func formatValues(values []float64) string {
+	formatted := make([]string, len(values))
+	for idx, value := range values {
+		formatted[idx] = fmt.Sprintf("%0.6f", value)
+	}
+	return strings.Join(formatted, " ")
+}
@@ -0,0 +1,121 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+const scoreCSV = `color,size,temperature
+red,5,cool
+blue,5,warm
+red,1,cool
+`
+
+func writeScoreData(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	modelPath := filepath.Join(dir, "model.json")
+	writePipeline(t, modelPath, 0.0)
+
+	dataPath := filepath.Join(dir, "data.csv")
+	if err := os.WriteFile(dataPath, []byte(scoreCSV), 0o644); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+	return modelPath, dataPath
+}
+
+func TestEvaluate(t *testing.T) {
+	modelPath, dataPath := writeScoreData(t)
+	reportPath := filepath.Join(filepath.Dir(dataPath), "report.txt")
+
+	err := evaluate([]string{"-model", modelPath, "-data", dataPath, "-target", "temperature", "-report", reportPath})
+	if err != nil {
+		t.Fatalf("Failed to evaluate: %v", err)
+	}
+
+	report, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+
+	if !strings.Contains(string(report), "classification error 0.0000") || !strings.Contains(string(report), "accuracy") {
+		t.Errorf("Expected a perfect classification report but got %s", report)
+	}
+
+	err = evaluate([]string{"-model", modelPath, "-data", dataPath})
+	if err == nil {
+		t.Errorf("Expected an error without a target column")
+	}
+}
+
+func TestWithInputs(t *testing.T) {
+	pipeline := gofeedforward.Pipeline{Network: gofeedforward.MakeNetwork(2, 1)}
+	if _, err := withInputs(pipeline, ""); err == nil {
+		t.Errorf("Expected an error for a bare network without input columns")
+	}
+
+	if _, err := withInputs(pipeline, "a,b,c"); err == nil {
+		t.Errorf("Expected an error for the wrong number of input columns")
+	}
+
+	pipeline, err := withInputs(pipeline, "a, b")
+	if err != nil {
+		t.Fatalf("Failed to add inputs: %v", err)
+	}
+
+	if len(pipeline.Fields) != 2 || pipeline.Fields[1].Name != "b" {
+		t.Errorf("Expected fields a and b but got %v", pipeline.Fields)
+	}
+}
+
+func TestWithInputsMetadata(t *testing.T) {
+	metadata := gofeedforward.MakeMetadata()
+	metadata.Inputs = []string{"height", "width"}
+	metadata.Classes = []string{"small"}
+	pipeline := gofeedforward.Pipeline{Network: gofeedforward.MakeNetwork(2, 1)}
+	pipeline.Network.Metadata = &metadata
+
+	withNames, err := withInputs(pipeline, "")
+	if err != nil || len(withNames.Fields) != 2 || withNames.Fields[0].Name != "height" {
+		t.Errorf("Expected the fields named in the metadata but got %v, %v", withNames.Fields, err)
+	}
+
+	if _, err := withInputs(pipeline, "width,height"); err == nil {
+		t.Error("Expected an error for inputs that do not match the metadata")
+	}
+
+	if classifier, err := classifierFor(pipeline, "", 0.0); err != nil || classifier == nil {
+		t.Errorf("Expected a classifier for the classes in the metadata but got %v", err)
+	}
+}
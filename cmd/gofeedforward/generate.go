@@ -0,0 +1,64 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/DarcInc/gofeedforward/codegen"
+)
+
+func generate(args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	path := flags.String("model", "model.json", "saved pipeline or network to generate code for")
+	pkg := flags.String("package", "model", "package of the generated file")
+	name := flags.String("func", "Predict", "name of the generated function")
+	output := flags.String("output", "", "file to write, instead of standard output")
+	flags.Parse(args)
+
+	pipeline, err := loadModel(*path)
+	if err != nil {
+		return err
+	}
+
+	if len(pipeline.Fields) > 0 || len(pipeline.Imputers) > 0 || len(pipeline.Scalers) > 0 || pipeline.OutputScaler != nil {
+		fmt.Fprintln(os.Stderr, "gofeedforward generate: only the network is generated, the inputs must already be encoded and scaled")
+	}
+
+	generator := codegen.Generator{Package: *pkg, FuncName: *name}
+	if *output == "" {
+		return generator.Write(os.Stdout, pipeline.Network)
+	}
+	return writeFile(*output, func(w io.Writer) error {
+		return generator.Write(w, pipeline.Network)
+	})
+}
@@ -0,0 +1,62 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	model := filepath.Join(dir, "model.json")
+	writePipeline(t, model, 0.0)
+
+	output := filepath.Join(dir, "score.go")
+	if err := generate([]string{"-model", model, "-package", "scoring", "-func", "Score", "-output", output}); err != nil {
+		t.Fatalf("Failed to generate: %v", err)
+	}
+
+	src, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+
+	for _, expected := range []string{"package scoring", "func Score(inputs [3]float64) [2]float64", "scoreL0N1W1 = 4.0"} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("Expected %q in\n%s", expected, src)
+		}
+	}
+
+	if err := generate([]string{"-model", model, "-func", "not valid", "-output", output}); err == nil {
+		t.Error("Expected an error for an invalid function name")
+	}
+}
@@ -0,0 +1,81 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/DarcInc/gofeedforward/predictor"
+	"google.golang.org/grpc"
+)
+
+func serveGRPC(args []string) error {
+	flags := flag.NewFlagSet("serve-grpc", flag.ExitOnError)
+	path := flags.String("model", "model.json", "saved pipeline or network to serve")
+	addr := flags.String("addr", ":9090", "address to listen on")
+	classes := flags.String("classes", "", "comma separated class names if the model has no classifier")
+	threshold := flags.Float64("threshold", 0.0, "use a threshold classifier with this threshold instead of best of")
+	flags.Parse(args)
+
+	pipeline, err := loadModel(*path)
+	if err != nil {
+		return err
+	}
+
+	classifier, err := classifierFor(pipeline, *classes, *threshold)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+
+	grpcServer := grpc.NewServer()
+	predictor.RegisterPredictorServer(grpcServer, predictor.MakeServer(pipeline, classifier))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		slog.Info("shutting down")
+		grpcServer.GracefulStop()
+	}()
+
+	slog.Info("serving gRPC", "addr", *addr, "model", *path)
+	return grpcServer.Serve(listener)
+}
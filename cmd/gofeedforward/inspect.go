@@ -0,0 +1,196 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+func inspect(args []string) error {
+	flags := flag.NewFlagSet("inspect", flag.ExitOnError)
+	path := flags.String("model", "model.json", "saved pipeline or network to inspect")
+	flags.Parse(args)
+
+	pipeline, err := loadModel(*path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("model %s\n\n", *path)
+	return writeInspection(os.Stdout, pipeline)
+}
+
+// weightStats summarizes the weights of a layer, including the bias weights.
+type weightStats struct {
+	min, max, mean, std float64
+	count               int
+}
+
+func statsOf(core gofeedforward.Core) weightStats {
+	stats := weightStats{min: math.Inf(1), max: math.Inf(-1)}
+	sum, sumSquares := 0.0, 0.0
+	for _, row := range core {
+		for _, weight := range row {
+			stats.min = math.Min(stats.min, weight)
+			stats.max = math.Max(stats.max, weight)
+			sum += weight
+			sumSquares += weight * weight
+			stats.count++
+		}
+	}
+
+	if stats.count > 0 {
+		n := float64(stats.count)
+		stats.mean = sum / n
+		stats.std = math.Sqrt(math.Max(sumSquares/n-stats.mean*stats.mean, 0.0))
+	}
+	return stats
+}
+
+// writeInspection writes the shape and weight statistics of each layer and a
+// description of the rest of the pipeline.
+func writeInspection(w io.Writer, pipeline gofeedforward.Pipeline) error {
+	net := pipeline.Network
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "layer\tinputs\toutputs\tparameters\tmin\tmax\tmean\tstd\t")
+
+	total := 0
+	for idx, layer := range net.Layers {
+		stats := statsOf(layer.Weights)
+		total += stats.count
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%0.4f\t%0.4f\t%0.4f\t%0.4f\t\n", idx, layer.Weights.InputSize()-1,
+			layer.Weights.OutputSize(), stats.count, stats.min, stats.max, stats.mean, stats.std)
+	}
+	fmt.Fprintf(tw, "total\t%d\t%d\t%d\t\t\t\t\t\n", net.InputSize(), net.OutputSize(), total)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(pipeline.Fields) > 0 {
+		fmt.Fprintln(w, "\nfields")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, field := range pipeline.Fields {
+			fmt.Fprintf(tw, "  %s\t%s\t\n", field.Name, describeField(field))
+		}
+		tw.Flush()
+	}
+
+	for _, imputer := range pipeline.Imputers {
+		fmt.Fprintf(w, "\nimputer %s on %s", imputer.Strategy, describeColumns(imputer.Columns))
+		if imputer.Indicators {
+			fmt.Fprint(w, " with indicators")
+		}
+		fmt.Fprintln(w)
+	}
+
+	for _, scaler := range pipeline.Scalers {
+		fmt.Fprintf(w, "\nscaler %s on %s\n", scaler.Method, describeColumns(scaler.Columns))
+	}
+
+	if pipeline.OutputScaler != nil {
+		fmt.Fprintf(w, "\noutput scaler %s on %s\n", pipeline.OutputScaler.Method, describeColumns(pipeline.OutputScaler.Columns))
+	}
+
+	if spec := pipeline.Classifier; spec != nil {
+		fmt.Fprintf(w, "\nclassifier %s: %s", spec.Type, strings.Join(spec.Classes, ", "))
+		if spec.Type == "threshold" {
+			fmt.Fprintf(w, " (threshold %v)", spec.Threshold)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if net.Metadata != nil {
+		writeMetadata(w, *net.Metadata)
+	}
+	return nil
+}
+
+// writeMetadata writes the provenance recorded with the network.
+func writeMetadata(w io.Writer, metadata gofeedforward.Metadata) {
+	fmt.Fprintln(w, "\nmetadata")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "  version\t%s\t\n", metadata.Version)
+	if !metadata.Created.IsZero() {
+		fmt.Fprintf(tw, "  created\t%s\t\n", metadata.Created.Format(time.RFC3339))
+	}
+	if len(metadata.Inputs) > 0 {
+		fmt.Fprintf(tw, "  inputs\t%s\t\n", strings.Join(metadata.Inputs, ", "))
+	}
+	if len(metadata.Outputs) > 0 {
+		fmt.Fprintf(tw, "  outputs\t%s\t\n", strings.Join(metadata.Outputs, ", "))
+	}
+	if len(metadata.Classes) > 0 {
+		fmt.Fprintf(tw, "  classes\t%s\t\n", strings.Join(metadata.Classes, ", "))
+	}
+	if metadata.DatasetHash != "" {
+		fmt.Fprintf(tw, "  dataset\t%s\t\n", metadata.DatasetHash)
+	}
+	if settings := metadata.Trainer; settings != nil {
+		fmt.Fprintf(tw, "  trainer\talpha %v, batch %v, shuffle rounds %d\t\n", settings.Alpha, settings.BatchUpdate, settings.ShuffleRounds)
+	}
+
+	names := []string{}
+	for name := range metadata.Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(tw, "  %s\t%v\t\n", name, metadata.Metrics[name])
+	}
+	tw.Flush()
+}
+
+func describeField(field gofeedforward.Field) string {
+	switch {
+	case field.OneHot != nil:
+		return fmt.Sprintf("onehot %s", strings.Join(field.OneHot.Categories, ", "))
+	case field.Ordinal != nil:
+		return fmt.Sprintf("ordinal %s", strings.Join(field.Ordinal.Categories, ", "))
+	case field.Hash != nil:
+		return fmt.Sprintf("hash into %d buckets", field.Hash.Buckets)
+	}
+	return "number"
+}
+
+func describeColumns(columns []int) string {
+	if len(columns) == 0 {
+		return "no columns"
+	}
+	return fmt.Sprintf("columns %v", columns)
+}
@@ -0,0 +1,68 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+func TestWriteInspection(t *testing.T) {
+	colors := gofeedforward.FitOneHotEncoder([]string{"red", "blue"}, 1.0, 0.0)
+	net := gofeedforward.MakeNetwork(3, 2, 1)
+	net.Layers[1].Weights[0] = []float64{-1.0, 1.0, 3.0}
+
+	pipeline := gofeedforward.Pipeline{
+		Fields:     []gofeedforward.Field{{Name: "color", OneHot: &colors}, {Name: "size"}},
+		Network:    net,
+		Scalers:    []gofeedforward.Scaler{{Method: gofeedforward.ZScoreScaling}},
+		Classifier: &gofeedforward.ClassifierSpec{Type: "threshold", Classes: []string{"hot"}, Threshold: 0.5},
+	}
+
+	metadata := gofeedforward.MakeMetadata()
+	metadata.Inputs = []string{"color=blue", "color=red", "size"}
+	metadata.Metrics = map[string]float64{"loss": 0.25}
+	pipeline.Network.Metadata = &metadata
+
+	var buf bytes.Buffer
+	if err := writeInspection(&buf, pipeline); err != nil {
+		t.Fatalf("Failed to inspect: %v", err)
+	}
+
+	out := buf.String()
+	for _, expected := range []string{"-1.0000  3.0000  1.0000", "total", "11", "onehot blue, red", "size", "classifier threshold: hot (threshold 0.5)", "scaler zscore on no columns",
+		"inputs   color=blue, color=red, size", "loss     0.25", "version  " + gofeedforward.Version} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected %q in\n%s", expected, out)
+		}
+	}
+}
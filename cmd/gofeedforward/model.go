@@ -0,0 +1,141 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+// loadModel reads either a saved Pipeline or a bare Network encoded as JSON.  A
+// bare network is wrapped in a pipeline with no fields, so it can only be used
+// with inputs that are already numeric.
+func loadModel(path string) (gofeedforward.Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return gofeedforward.Pipeline{}, err
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return gofeedforward.Pipeline{}, fmt.Errorf("%s: %v", path, err)
+	}
+
+	if _, ok := probe["Network"]; ok {
+		pipeline, err := gofeedforward.LoadPipeline(bytes.NewReader(data))
+		if err != nil {
+			return gofeedforward.Pipeline{}, fmt.Errorf("%s: %v", path, err)
+		}
+		return pipeline, nil
+	}
+
+	net, err := gofeedforward.LoadNetwork(bytes.NewReader(data))
+	if err != nil {
+		return gofeedforward.Pipeline{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return gofeedforward.Pipeline{Network: net}, nil
+}
+
+// classifierFor returns the pipeline's classifier, or one made from a comma
+// separated list of classes, or the classes in the network's metadata, when
+// the pipeline does not have one.  A threshold greater than zero makes a
+// threshold classifier instead of a best of classifier.  It returns nil if
+// there is no way to classify.
+func classifierFor(pipeline gofeedforward.Pipeline, classes string, threshold float64) (gofeedforward.BasicClassifier, error) {
+	if pipeline.Classifier != nil {
+		return pipeline.Classifier.Classifier()
+	}
+
+	if classes == "" {
+		if metadata := pipeline.Network.Metadata; metadata != nil && len(metadata.Classes) > 0 {
+			classes = strings.Join(metadata.Classes, ",")
+		} else {
+			return nil, nil
+		}
+	}
+
+	names := strings.Split(classes, ",")
+	if len(names) != pipeline.Network.OutputSize() {
+		return nil, fmt.Errorf("%d classes given for a network with %d outputs", len(names), pipeline.Network.OutputSize())
+	}
+
+	if threshold > 0.0 {
+		return gofeedforward.MakeThresholdClassifier(names, threshold), nil
+	}
+	return gofeedforward.MakeBestOfClassifier(names), nil
+}
+
+// withInputs gives a pipeline without fields, such as a bare network, a number
+// field for each of the comma separated input columns so that it can read
+// records.  The columns default to the inputs named in the network's metadata
+// and must match them when both are given.  A pipeline with fields of its own
+// is returned unchanged.
+func withInputs(pipeline gofeedforward.Pipeline, columns string) (gofeedforward.Pipeline, error) {
+	if len(pipeline.Fields) > 0 {
+		return pipeline, nil
+	}
+
+	if columns == "" {
+		metadata := pipeline.Network.Metadata
+		if metadata == nil || len(metadata.Inputs) == 0 {
+			return pipeline, fmt.Errorf("model has no fields, so the input columns must be given")
+		}
+		columns = strings.Join(metadata.Inputs, ",")
+	}
+
+	for _, name := range strings.Split(columns, ",") {
+		pipeline.Fields = append(pipeline.Fields, gofeedforward.Field{Name: strings.TrimSpace(name)})
+	}
+
+	if err := pipeline.Validate(); err != nil {
+		return pipeline, err
+	}
+	return pipeline, nil
+}
+
+// dataFor describes data read with the pipeline's fields as the input columns
+// and the comma separated target columns.
+func dataFor(pipeline gofeedforward.Pipeline, targets, comma string) dataConfig {
+	config := dataConfig{Comma: comma}
+	for _, field := range pipeline.Fields {
+		config.Inputs = append(config.Inputs, fieldConfig{Column: field.Name})
+	}
+
+	if targets != "" {
+		for _, name := range strings.Split(targets, ",") {
+			config.Target.Columns = append(config.Target.Columns, strings.TrimSpace(name))
+		}
+	}
+	return config
+}
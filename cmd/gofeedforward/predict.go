@@ -0,0 +1,141 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+func predict(args []string) error {
+	flags := flag.NewFlagSet("predict", flag.ExitOnError)
+	path := flags.String("model", "model.json", "saved pipeline or network to predict with")
+	dataPath := flags.String("data", "", "CSV file, with a header row, to score")
+	outputPath := flags.String("output", "", "CSV file to write, standard output if not given")
+	inputs := flags.String("inputs", "", "comma separated input columns if the model has no fields")
+	comma := flags.String("comma", "", "field delimiter if not a comma")
+	classes := flags.String("classes", "", "comma separated class names if the model has no classifier")
+	threshold := flags.Float64("threshold", 0.0, "use a threshold classifier with this threshold instead of best of")
+	flags.Parse(args)
+
+	if *dataPath == "" {
+		return fmt.Errorf("-data is required")
+	}
+
+	pipeline, err := loadModel(*path)
+	if err != nil {
+		return err
+	}
+
+	classifier, err := classifierFor(pipeline, *classes, *threshold)
+	if err != nil {
+		return err
+	}
+
+	if pipeline, err = withInputs(pipeline, *inputs); err != nil {
+		return err
+	}
+
+	header, records, err := dataFor(pipeline, "", *comma).read(*dataPath)
+	if err != nil {
+		return err
+	}
+
+	if *outputPath == "" {
+		return writePredictions(os.Stdout, *comma, pipeline, classifier, header, records)
+	}
+
+	return writeFile(*outputPath, func(w io.Writer) error {
+		return writePredictions(w, *comma, pipeline, classifier, header, records)
+	})
+}
+
+// writePredictions writes each row followed by the network outputs, in their
+// original units, and the classes if there is a classifier.  More than one
+// class is joined with a space.
+func writePredictions(out io.Writer, comma string, pipeline gofeedforward.Pipeline, classifier gofeedforward.BasicClassifier, header []string, records []gofeedforward.CSVRecord) error {
+	w := csv.NewWriter(out)
+	if comma != "" {
+		w.Comma, _ = utf8.DecodeRuneInString(comma)
+	}
+
+	columns := append([]string{}, header...)
+	for idx := 0; idx < pipeline.Network.OutputSize(); idx++ {
+		columns = append(columns, "output_"+strconv.Itoa(idx))
+	}
+	if classifier != nil {
+		columns = append(columns, "class")
+	}
+	w.Write(columns)
+
+	for _, record := range records {
+		inputs, err := pipeline.Inputs(record.Inputs)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", record.Line, err)
+		}
+
+		outputs, err := pipeline.Network.Process(inputs)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", record.Line, err)
+		}
+
+		var classes []string
+		if classifier != nil {
+			if classes, err = classifier(outputs); err != nil {
+				return fmt.Errorf("line %d: %v", record.Line, err)
+			}
+		}
+
+		if pipeline.OutputScaler != nil {
+			if outputs, err = pipeline.OutputScaler.Inverse(outputs); err != nil {
+				return fmt.Errorf("line %d: %v", record.Line, err)
+			}
+		}
+
+		result := append([]string{}, record.Row...)
+		for _, output := range outputs {
+			result = append(result, strconv.FormatFloat(output, 'g', -1, 64))
+		}
+		if classifier != nil {
+			result = append(result, strings.Join(classes, " "))
+		}
+		w.Write(result)
+	}
+
+	w.Flush()
+	return w.Error()
+}
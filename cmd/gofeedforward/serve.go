@@ -0,0 +1,347 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+// model is a loaded pipeline and its classifier.  A network keeps the state of
+// its last evaluation, so requests take turns with the mutex.
+type model struct {
+	mu         sync.Mutex
+	pipeline   gofeedforward.Pipeline
+	classifier gofeedforward.BasicClassifier
+	modTime    time.Time
+}
+
+// maxRequestBytes limits the size of a request body.
+const maxRequestBytes = 10 << 20
+
+// server serves predictions from the model file, reloading it when it changes.
+// If reloaded is set, watch calls it with the result of every reload.
+type server struct {
+	path      string
+	classes   string
+	threshold float64
+	current   atomic.Pointer[model]
+	metrics   *gofeedforward.Metrics
+	logger    *slog.Logger
+	reloaded  func(error)
+}
+
+// batchRequest is the body of a predict or classify request.  Inputs are
+// presented to the network as they are, while records are raw values that go
+// through the pipeline's fields, imputers and scalers first.  A model with
+// imputers or scalers only accepts records, since inputs would skip them.
+type batchRequest struct {
+	Inputs  [][]float64 `json:"inputs"`
+	Records [][]string  `json:"records"`
+}
+
+type predictResponse struct {
+	Outputs [][]float64 `json:"outputs"`
+}
+
+type classifyResponse struct {
+	Classes [][]string `json:"classes"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	path := flags.String("model", "model.json", "saved pipeline or network to serve")
+	addr := flags.String("addr", ":8080", "address to listen on")
+	classes := flags.String("classes", "", "comma separated class names if the model has no classifier")
+	threshold := flags.Float64("threshold", 0.0, "use a threshold classifier with this threshold instead of best of")
+	reload := flags.Duration("reload", 5*time.Second, "how often to check the model file for changes, 0 to disable")
+	flags.Parse(args)
+
+	s := &server{
+		path:      *path,
+		classes:   *classes,
+		threshold: *threshold,
+		metrics:   gofeedforward.MakeMetrics(),
+		logger:    slog.Default(),
+	}
+
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go s.watch(ctx, *reload)
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("serving", "addr", *addr, "model", *path)
+	return s.run(ctx, &http.Server{Handler: s.handler()}, listener)
+}
+
+// run serves HTTP on the listener until the context is done and then shuts
+// down gracefully.  Serve returns as soon as the shutdown starts, so run waits
+// for the shutdown to finish draining in-flight requests before returning.
+func (s *server) run(ctx context.Context, httpServer *http.Server, listener net.Listener) error {
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		s.logger.Info("shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		done <- httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-done
+}
+
+// reload loads the model file and replaces the current model.  The current
+// model is kept if the file cannot be loaded.
+func (s *server) reload() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+
+	pipeline, err := loadModel(s.path)
+	if err != nil {
+		return err
+	}
+
+	classifier, err := classifierFor(pipeline, s.classes, s.threshold)
+	if err != nil {
+		return err
+	}
+
+	s.current.Store(&model{pipeline: pipeline, classifier: classifier, modTime: info.ModTime()})
+	s.logger.Info("loaded model", "model", s.path, "inputs", pipeline.Network.InputSize(),
+		"outputs", pipeline.Network.OutputSize())
+	return nil
+}
+
+// watch reloads the model when the file's modification time changes or the
+// process receives SIGHUP, until the context is done.
+func (s *server) watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-tick:
+			info, err := os.Stat(s.path)
+			if err != nil || !info.ModTime().After(s.current.Load().modTime) {
+				continue
+			}
+		}
+
+		err := s.reload()
+		if err != nil {
+			s.logger.Error("failed to reload model", "model", s.path, "error", err)
+		}
+
+		if s.reloaded != nil {
+			s.reloaded(err)
+		}
+	}
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/predict", s.handlePredict)
+	mux.HandleFunc("/classify", s.handleClassify)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if s.current.Load() == nil {
+			http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+	mux.Handle("/metrics", s.metrics)
+	return mux
+}
+
+// inputs turns a batch request into network inputs for the model.
+func (m *model) inputs(req batchRequest) ([][]float64, error) {
+	if len(req.Records) == 0 {
+		if len(req.Inputs) > 0 && (len(m.pipeline.Imputers) > 0 || len(m.pipeline.Scalers) > 0) {
+			return nil, fmt.Errorf("model has imputers or scalers, so it only accepts records")
+		}
+		return req.Inputs, nil
+	}
+
+	if len(req.Inputs) > 0 {
+		return nil, fmt.Errorf("send either inputs or records, not both")
+	}
+
+	if len(m.pipeline.Fields) == 0 {
+		return nil, fmt.Errorf("model has no fields, so it only accepts inputs")
+	}
+
+	result := [][]float64{}
+	for idx, record := range req.Records {
+		inputs, err := m.pipeline.Inputs(record)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %v", idx, err)
+		}
+		result = append(result, inputs)
+	}
+	return result, nil
+}
+
+// process runs each set of inputs through the network, passing the outputs to
+// the given function.  It writes an error response and returns false if the
+// request cannot be processed.
+func (s *server) process(w http.ResponseWriter, r *http.Request, each func(*model, []float64) error) bool {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "use POST"})
+		return false
+	}
+
+	m := s.current.Load()
+	if m == nil {
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "model not loaded"})
+		return false
+	}
+
+	var req batchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeJSON(w, status, errorResponse{Error: err.Error()})
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	batch, err := m.inputs(req)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return false
+	}
+
+	for idx, inputs := range batch {
+		outputs, err := s.metrics.Process(&m.pipeline.Network, inputs)
+		if err == nil {
+			err = each(m, outputs)
+		}
+
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("example %d: %v", idx, err)})
+			return false
+		}
+	}
+	return true
+}
+
+func (s *server) handlePredict(w http.ResponseWriter, r *http.Request) {
+	resp := predictResponse{Outputs: [][]float64{}}
+	ok := s.process(w, r, func(m *model, outputs []float64) error {
+		if m.pipeline.OutputScaler != nil {
+			var err error
+			if outputs, err = m.pipeline.OutputScaler.Inverse(outputs); err != nil {
+				return err
+			}
+		}
+		resp.Outputs = append(resp.Outputs, append([]float64{}, outputs...))
+		return nil
+	})
+
+	if ok {
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *server) handleClassify(w http.ResponseWriter, r *http.Request) {
+	resp := classifyResponse{Classes: [][]string{}}
+	ok := s.process(w, r, func(m *model, outputs []float64) error {
+		if m.classifier == nil {
+			return fmt.Errorf("model has no classifier")
+		}
+
+		classes, err := m.classifier(outputs)
+		if err != nil {
+			return err
+		}
+		resp.Classes = append(resp.Classes, classes)
+		return nil
+	})
+
+	if ok {
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
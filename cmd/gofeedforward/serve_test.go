@@ -0,0 +1,291 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+func writePipeline(t *testing.T, path string, bias float64) {
+	colors := gofeedforward.FitOneHotEncoder([]string{"red", "blue"}, 1.0, 0.0)
+	net := gofeedforward.MakeNetwork(3, 2)
+	net.Layers[0].Weights[0] = []float64{4.0, -4.0, 0.0, bias}
+	net.Layers[0].Weights[1] = []float64{-4.0, 4.0, 0.0, -bias}
+
+	pipeline := gofeedforward.Pipeline{
+		Fields:     []gofeedforward.Field{{Name: "color", OneHot: &colors}, {Name: "size"}},
+		Network:    net,
+		Classifier: &gofeedforward.ClassifierSpec{Type: "best", Classes: []string{"warm", "cool"}},
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer file.Close()
+	pipeline.Save(file)
+}
+
+func testServer(t *testing.T) (*server, *httptest.Server, string) {
+	path := filepath.Join(t.TempDir(), "model.json")
+	writePipeline(t, path, 0.0)
+
+	s := &server{path: path, metrics: gofeedforward.MakeMetrics(), logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	if err := s.reload(); err != nil {
+		t.Fatalf("Failed to load model: %v", err)
+	}
+
+	ts := httptest.NewServer(s.handler())
+	t.Cleanup(ts.Close)
+	return s, ts, path
+}
+
+func post(t *testing.T, url, body string, result any) int {
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to post: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestServePredict(t *testing.T) {
+	_, ts, _ := testServer(t)
+
+	var resp predictResponse
+	status := post(t, ts.URL+"/predict", `{"inputs": [[0, 1, 5]], "records": []}`, &resp)
+	if status != http.StatusOK || len(resp.Outputs) != 1 || resp.Outputs[0][1] < 0.9 {
+		t.Errorf("Unexpected response %d %v", status, resp)
+	}
+
+	status = post(t, ts.URL+"/predict", `{"records": [["red", "5"], ["blue", "5"]]}`, &resp)
+	if status != http.StatusOK || len(resp.Outputs) != 2 || resp.Outputs[1][0] < 0.9 {
+		t.Errorf("Unexpected response %d %v", status, resp)
+	}
+
+	var errResp errorResponse
+	status = post(t, ts.URL+"/predict", `{"records": [["green", "5"]]}`, &errResp)
+	if status != http.StatusBadRequest || !strings.Contains(errResp.Error, "green") {
+		t.Errorf("Expected a bad request for an unknown category but got %d %v", status, errResp)
+	}
+}
+
+func TestServeClassify(t *testing.T) {
+	_, ts, _ := testServer(t)
+
+	var resp classifyResponse
+	status := post(t, ts.URL+"/classify", `{"records": [["red", "5"], ["blue", "5"]]}`, &resp)
+	if status != http.StatusOK || len(resp.Classes) != 2 || resp.Classes[0][0] != "cool" || resp.Classes[1][0] != "warm" {
+		t.Errorf("Unexpected response %d %v", status, resp)
+	}
+}
+
+func TestServeHealth(t *testing.T) {
+	_, ts, _ := testServer(t)
+
+	for _, path := range []string{"/healthz", "/readyz", "/metrics"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected %s to be OK but got %v, %v", path, resp, err)
+		}
+		resp.Body.Close()
+	}
+
+	empty := httptest.NewServer((&server{metrics: gofeedforward.MakeMetrics()}).handler())
+	defer empty.Close()
+	if resp, _ := http.Get(empty.URL + "/readyz"); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected a server without a model not to be ready but got %d", resp.StatusCode)
+	}
+}
+
+func TestServeReload(t *testing.T) {
+	s, ts, path := testServer(t)
+
+	writePipeline(t, path, 20.0)
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("Failed to touch model: %v", err)
+	}
+
+	reloaded := make(chan error, 1)
+	s.reloaded = func(err error) {
+		select {
+		case reloaded <- err:
+		default:
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.watch(ctx, 10*time.Millisecond)
+
+	if err := <-reloaded; err != nil {
+		t.Fatalf("Failed to reload model: %v", err)
+	}
+
+	var resp classifyResponse
+	post(t, ts.URL+"/classify", `{"records": [["red", "5"]]}`, &resp)
+	if len(resp.Classes) != 1 || resp.Classes[0][0] != "warm" {
+		t.Errorf("Expected the reloaded model to classify red as warm but got %v", resp)
+	}
+}
+
+func TestServeRejectsInputs(t *testing.T) {
+	s, ts, _ := testServer(t)
+
+	m := s.current.Load()
+	scaled := &model{pipeline: m.pipeline, classifier: m.classifier, modTime: m.modTime}
+	scaled.pipeline.Scalers = []gofeedforward.Scaler{{Method: gofeedforward.ZScoreScaling, Columns: []int{2}, Center: []float64{5.0}, Spread: []float64{1.0}}}
+	s.current.Store(scaled)
+
+	var errResp errorResponse
+	status := post(t, ts.URL+"/predict", `{"inputs": [[0, 1, 5]]}`, &errResp)
+	if status != http.StatusBadRequest || !strings.Contains(errResp.Error, "records") {
+		t.Errorf("Expected inputs to be rejected for a model with scalers but got %d %v", status, errResp)
+	}
+
+	var resp predictResponse
+	if status := post(t, ts.URL+"/predict", `{"records": [["blue", "5"]]}`, &resp); status != http.StatusOK {
+		t.Errorf("Expected records to be accepted but got %d", status)
+	}
+
+	body := `{"inputs": [[` + strings.Repeat("0,", maxRequestBytes/2) + `0]]}`
+	status = post(t, ts.URL+"/predict", body, &errResp)
+	if status != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected a request that is too large to be rejected but got %d %v", status, errResp)
+	}
+}
+
+func TestLoadModelNetwork(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "net.json")
+	data, _ := json.Marshal(gofeedforward.MakeNetwork(2, 3, 2))
+	os.WriteFile(path, data, 0644)
+
+	pipeline, err := loadModel(path)
+	if err != nil {
+		t.Fatalf("Failed to load network: %v", err)
+	}
+
+	if pipeline.Network.InputSize() != 2 || len(pipeline.Fields) != 0 {
+		t.Errorf("Unexpected pipeline %v", pipeline)
+	}
+
+	classifier, err := classifierFor(pipeline, "a,b", 0.0)
+	if err != nil || classifier == nil {
+		t.Errorf("Expected a classifier but got %v", err)
+	}
+
+	if _, err := classifierFor(pipeline, "a,b,c", 0.0); err == nil {
+		t.Error("Expected an error for the wrong number of classes")
+	}
+
+	os.WriteFile(path, bytes.Repeat([]byte("x"), 10), 0644)
+	if _, err := loadModel(path); err == nil {
+		t.Error("Expected an error for a file that is not JSON")
+	}
+}
+
+func TestServeGracefulShutdown(t *testing.T) {
+	s := &server{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished atomic.Bool
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte("done"))
+		finished.Store(true)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	httpServer := &http.Server{Handler: handler}
+	shuttingDown := make(chan struct{})
+	httpServer.RegisterOnShutdown(func() { close(shuttingDown) })
+
+	result := make(chan error, 1)
+	go func() {
+		result <- s.run(ctx, httpServer, listener)
+	}()
+
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+
+	<-started
+	cancel()
+	<-shuttingDown
+	close(release)
+
+	if err := <-result; err != nil {
+		t.Errorf("Expected a clean shutdown but got %v", err)
+	}
+
+	if !finished.Load() {
+		t.Error("Expected the server to wait for the in-flight request before returning")
+	}
+
+	if body := <-response; body != "done" {
+		t.Errorf("Expected the in-flight request to finish but got %q", body)
+	}
+}
@@ -0,0 +1,272 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand"
+	"os"
+	"text/tabwriter"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+func train(args []string) error {
+	flags := flag.NewFlagSet("train", flag.ExitOnError)
+	configPath := flags.String("config", "train.yaml", "YAML or JSON file describing the training run")
+	flags.Parse(args)
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	_, records, err := config.Data.read(config.Data.Train)
+	if err != nil {
+		return err
+	}
+
+	datasetHash, err := hashFile(config.Data.Train)
+	if err != nil {
+		return err
+	}
+
+	// The validation records are set aside before the pipeline is fit, so
+	// nothing about them leaks into the encoders, imputers or scalers.
+	var held []gofeedforward.CSVRecord
+	heldPath := config.Data.Validation
+	if config.Data.Validation != "" {
+		if _, held, err = config.Data.read(config.Data.Validation); err != nil {
+			return err
+		}
+	} else if config.Data.Split > 0.0 && config.Data.Split < 1.0 {
+		records, held = splitRecords(records, config.Data.Split)
+		heldPath = config.Data.Train
+	}
+
+	pipeline, err := config.Data.fitPipeline(records)
+	if err != nil {
+		return fmt.Errorf("%s: %v", config.Data.Train, err)
+	}
+
+	training, err := config.Data.prepare(pipeline, records)
+	if err != nil {
+		return fmt.Errorf("%s: %v", config.Data.Train, err)
+	}
+
+	var validation gofeedforward.TrainingData
+	if len(held) > 0 {
+		if validation, err = config.Data.prepare(pipeline, held); err != nil {
+			return fmt.Errorf("%s: %v", heldPath, err)
+		}
+	}
+
+	layers := config.Network.Layers
+	inputs, outputs := len(training[0].Inputs), len(training[0].Expected)
+	if layers[0] != inputs || layers[len(layers)-1] != outputs {
+		return fmt.Errorf("the data has %d inputs and %d outputs, so the layers must start with %d and end with %d",
+			inputs, outputs, inputs, outputs)
+	}
+
+	net := gofeedforward.MakeNetwork(layers...)
+	net.Randomize()
+
+	trainer := gofeedforward.Trainer{
+		Alpha:         config.Trainer.Alpha,
+		BatchUpdate:   config.Trainer.Batch,
+		ShuffleRounds: config.Trainer.ShuffleRounds,
+		OutputWeights: config.Trainer.OutputWeights,
+	}
+	trainer.AddSimpleStoppingCriteria(config.Trainer.MaxIterations, config.Trainer.MinError)
+	trainer.AddProgressLogger(slog.Default(), config.Trainer.LogEvery, config.Trainer.MaxIterations)
+	history := trainer.AddHistory(&net, validation)
+
+	if err := trainer.Train(&net, training); err != nil {
+		return err
+	}
+
+	metadata := config.metadata(pipeline, trainer, datasetHash, history)
+	net.Metadata = &metadata
+	pipeline.Network = net
+
+	if err := writeFile(config.Output.Model, pipeline.Save); err != nil {
+		return err
+	}
+	slog.Info("saved model", "model", config.Output.Model)
+
+	if config.Output.History != "" {
+		if err := writeFile(config.Output.History, history.WriteCSV); err != nil {
+			return err
+		}
+	}
+
+	var classifier gofeedforward.BasicClassifier
+	if pipeline.Classifier != nil {
+		if classifier, err = pipeline.Classifier.Classifier(); err != nil {
+			return err
+		}
+	}
+
+	var report bytes.Buffer
+	if err := writeReport(&report, "training", pipeline, classifier, training); err != nil {
+		return err
+	}
+
+	if len(validation) > 0 {
+		report.WriteString("\n")
+		if err := writeReport(&report, "validation", pipeline, classifier, validation); err != nil {
+			return err
+		}
+	}
+
+	os.Stdout.Write(report.Bytes())
+	if config.Output.Report != "" {
+		return os.WriteFile(config.Output.Report, report.Bytes(), 0o644)
+	}
+	return nil
+}
+
+// hashFile returns a SHA-256 hash of the file's contents.  Unlike
+// HashTrainingData, it does not depend on how the rows are split or shuffled
+// during training, so the same file always has the same hash.
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// splitRecords shuffles a copy of the records and splits it the way
+// TrainingData.Split does, with at least the fraction of the records in the
+// first part and the rest in the second.
+func splitRecords(records []gofeedforward.CSVRecord, fraction float64) ([]gofeedforward.CSVRecord, []gofeedforward.CSVRecord) {
+	shuffled := append([]gofeedforward.CSVRecord{}, records...)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	count := int(math.Ceil(float64(len(shuffled)) * fraction))
+	return shuffled[:count], shuffled[count:]
+}
+
+// metadata describes the inputs, outputs and classes of the trained pipeline
+// along with the hash of the training file, hyperparameters and final losses.
+func (c trainConfig) metadata(pipeline gofeedforward.Pipeline, trainer gofeedforward.Trainer, datasetHash string, history *gofeedforward.History) gofeedforward.Metadata {
+	settings := trainer.Settings()
+	metadata := gofeedforward.MakeMetadata()
+	metadata.Inputs = pipeline.InputNames()
+	metadata.Trainer = &settings
+	metadata.DatasetHash = datasetHash
+
+	target := c.Data.Target
+	if target.Encoding == "onehot" {
+		for _, class := range pipeline.Classifier.Classes {
+			metadata.Outputs = append(metadata.Outputs, target.Columns[0]+"="+class)
+		}
+		metadata.Classes = pipeline.Classifier.Classes
+	} else {
+		metadata.Outputs = target.Columns
+	}
+
+	if len(history.Entries) > 0 {
+		last := history.Entries[len(history.Entries)-1]
+		metadata.Metrics = map[string]float64{"iterations": float64(last.Iteration), "loss": last.Loss}
+		if last.ValidationErrors != nil {
+			metadata.Metrics["validation_loss"] = last.ValidationLoss
+		}
+	}
+	return metadata
+}
+
+// writeFile creates the file and passes it to the write function.
+func writeFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return file.Close()
+}
+
+// writeReport writes a classification report if there is a classifier and
+// regression metrics, in the original units of the outputs, otherwise.
+func writeReport(w io.Writer, name string, pipeline gofeedforward.Pipeline, classifier gofeedforward.BasicClassifier, data gofeedforward.TrainingData) error {
+	fmt.Fprintf(w, "%s (%d examples)\n\n", name, len(data))
+
+	if classifier != nil {
+		report, err := gofeedforward.MakeClassificationReport(pipeline.Network, data, classifier)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(w, report.String())
+		return nil
+	}
+
+	predictions, err := gofeedforward.EvaluatePredictions(pipeline.Network, data)
+	if err != nil {
+		return err
+	}
+
+	if pipeline.OutputScaler != nil {
+		for idx, prediction := range predictions {
+			if predictions[idx].Expected, err = pipeline.OutputScaler.Inverse(prediction.Expected); err != nil {
+				return err
+			}
+			if predictions[idx].Outputs, err = pipeline.OutputScaler.Inverse(prediction.Outputs); err != nil {
+				return err
+			}
+		}
+	}
+
+	metrics, err := predictions.RegressionMetrics()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "output\trmse\tmae\tr2\t")
+	for idx, m := range metrics {
+		fmt.Fprintf(tw, "%d\t%0.4f\t%0.4f\t%0.4f\t\n", idx, m.RMSE, m.MAE, m.RSquared)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,303 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+const trainCSV = `color,size,temperature,weight
+red,1,cool,1
+blue,2,warm,1
+red,3,cool,2
+blue,4,warm,1
+red,,cool,1
+blue,6,warm,1
+`
+
+func writeTrainConfig(t *testing.T, config string) string {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "train.csv"), []byte(trainCSV), 0o644); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+
+	path := filepath.Join(dir, "train.yaml")
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestTrain(t *testing.T) {
+	path := writeTrainConfig(t, `
+network:
+  layers: [3, 4, 2]
+trainer:
+  alpha: 0.5
+  max_iterations: 200
+  log_every: 1000
+data:
+  train: train.csv
+  validation: train.csv
+  inputs:
+    - column: color
+      encoding: onehot
+    - column: size
+  target:
+    columns: [temperature]
+    encoding: onehot
+  weight: weight
+  impute: median
+  scale: minmax
+output:
+  model: model.json
+  report: report.txt
+  history: history.csv
+`)
+
+	if err := train([]string{"-config", path}); err != nil {
+		t.Fatalf("Failed to train: %v", err)
+	}
+
+	dir := filepath.Dir(path)
+	pipeline, err := loadModel(filepath.Join(dir, "model.json"))
+	if err != nil {
+		t.Fatalf("Failed to load the trained model: %v", err)
+	}
+
+	if pipeline.Classifier == nil || len(pipeline.Classifier.Classes) != 2 {
+		t.Errorf("Expected a classifier for 2 classes but got %v", pipeline.Classifier)
+	}
+
+	if len(pipeline.Imputers) != 1 || len(pipeline.Scalers) != 1 {
+		t.Errorf("Expected an imputer and a scaler but got %v and %v", pipeline.Imputers, pipeline.Scalers)
+	}
+
+	metadata := pipeline.Network.Metadata
+	if metadata == nil || strings.Join(metadata.Inputs, ",") != "color=blue,color=red,size" ||
+		strings.Join(metadata.Outputs, ",") != "temperature=cool,temperature=warm" || len(metadata.Classes) != 2 ||
+		metadata.Trainer == nil || metadata.Trainer.Alpha != 0.5 || metadata.DatasetHash == "" || metadata.Metrics["iterations"] == 0 {
+		t.Errorf("Unexpected metadata %+v", metadata)
+	}
+
+	if _, err := pipeline.Classify([]string{"red", "NA"}); err != nil {
+		t.Errorf("Failed to classify with the trained model: %v", err)
+	}
+
+	report, err := os.ReadFile(filepath.Join(dir, "report.txt"))
+	if err != nil || !strings.Contains(string(report), "validation") || !strings.Contains(string(report), "accuracy") {
+		t.Errorf("Expected a report with validation metrics but got %q %v", report, err)
+	}
+
+	history, err := os.ReadFile(filepath.Join(dir, "history.csv"))
+	if err != nil || strings.Count(string(history), "\n") < 2 {
+		t.Errorf("Expected a training history but got %q %v", history, err)
+	}
+}
+
+func TestTrain_Regression(t *testing.T) {
+	config := `{
+  "network": {"layers": [1, 2, 1]},
+  "trainer": {"max_iterations": 10, "log_every": 1000},
+  "data": {
+    "train": "train.csv",
+    "inputs": [{"column": "color", "encoding": "ordinal"}],
+    "target": {"columns": ["TARGET"], "scale": true}
+  },
+  "output": {"model": "model.json", "report": "report.txt"}
+}`
+
+	path := writeTrainConfig(t, strings.Replace(config, "TARGET", "weight", 1))
+	if err := train([]string{"-config", path}); err != nil {
+		t.Fatalf("Failed to train: %v", err)
+	}
+
+	report, err := os.ReadFile(filepath.Join(filepath.Dir(path), "report.txt"))
+	if err != nil || !strings.Contains(string(report), "rmse") {
+		t.Errorf("Expected a report with regression metrics but got %q %v", report, err)
+	}
+
+	path = writeTrainConfig(t, strings.Replace(config, "TARGET", "size", 1))
+	if err := train([]string{"-config", path}); err == nil || !strings.Contains(err.Error(), "not a number") {
+		t.Errorf("Expected an error for the missing target value but got %v", err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cases := map[string]string{
+		"relu":       "network: {layers: [2, 1], activation: relu}\ndata: {train: a.csv, inputs: [{column: a}], target: {columns: [b]}}",
+		"layer size": "network: {layers: [2]}\ndata: {train: a.csv, inputs: [{column: a}], target: {columns: [b]}}",
+		"no target":  "network: {layers: [2, 1]}\ndata: {train: a.csv, inputs: [{column: a}]}",
+		"onehot":     "network: {layers: [2, 1]}\ndata: {train: a.csv, inputs: [{column: a}], target: {columns: [b, c], encoding: onehot}}",
+		"unknown":    "network: {layers: [2, 1]}\ndata: {train: a.csv, inputs: [{column: a}], target: {columns: [b]}}\nlearning_rate: 0.1",
+	}
+
+	for name, config := range cases {
+		if _, err := loadConfig(writeTrainConfig(t, config)); err == nil {
+			t.Errorf("%s: expected an invalid config", name)
+		}
+	}
+
+	config, err := loadConfig(writeTrainConfig(t, "network: {layers: [2, 1]}\ndata: {train: a.csv, inputs: [{column: a}], target: {columns: [b]}}"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Trainer.Alpha != 0.1 || config.Trainer.MaxIterations != 1000 || !filepath.IsAbs(config.Data.Train) {
+		t.Errorf("Expected defaults and an absolute training path but got %+v", config)
+	}
+}
+
+func TestTrain_ConstantImpute(t *testing.T) {
+	path := writeTrainConfig(t, `
+network:
+  layers: [1, 1]
+trainer:
+  max_iterations: 5
+  log_every: 1000
+data:
+  train: train.csv
+  inputs:
+    - column: size
+  target:
+    columns: [weight]
+  impute: constant
+  impute_value: 7
+`)
+
+	if err := train([]string{"-config", path}); err != nil {
+		t.Fatalf("Failed to train: %v", err)
+	}
+
+	pipeline, err := loadModel(filepath.Join(filepath.Dir(path), "model.json"))
+	if err != nil {
+		t.Fatalf("Failed to load the trained model: %v", err)
+	}
+
+	if len(pipeline.Imputers) != 1 || pipeline.Imputers[0].Strategy != "constant" || pipeline.Imputers[0].Values[0] != 7.0 {
+		t.Errorf("Expected a constant imputer for 7 but got %v", pipeline.Imputers)
+	}
+}
+
+func TestTrain_Split(t *testing.T) {
+	path := writeTrainConfig(t, `
+network:
+  layers: [1, 1]
+trainer:
+  max_iterations: 5
+  log_every: 1000
+data:
+  train: train.csv
+  split: 0.5
+  inputs:
+    - column: size
+  target:
+    columns: [weight]
+  impute: median
+output:
+  report: report.txt
+`)
+
+	if err := train([]string{"-config", path}); err != nil {
+		t.Fatalf("Failed to train: %v", err)
+	}
+
+	report, err := os.ReadFile(filepath.Join(filepath.Dir(path), "report.txt"))
+	if err != nil || !strings.Contains(string(report), "training (3 examples)") || !strings.Contains(string(report), "validation (3 examples)") {
+		t.Errorf("Expected 3 training and 3 validation examples but got %q %v", report, err)
+	}
+}
+
+func TestSplitRecords(t *testing.T) {
+	records := []gofeedforward.CSVRecord{}
+	for line := 2; line < 9; line++ {
+		records = append(records, gofeedforward.CSVRecord{Line: line})
+	}
+
+	training, validation := splitRecords(records, 0.5)
+	if len(training) != 4 || len(validation) != 3 {
+		t.Fatalf("Expected 4 and 3 records but got %d and %d", len(training), len(validation))
+	}
+
+	seen := map[int]bool{}
+	for _, record := range append(training, validation...) {
+		seen[record.Line] = true
+	}
+	if len(seen) != len(records) {
+		t.Errorf("Expected every record in exactly one part but got %v and %v", training, validation)
+	}
+
+	for idx, record := range records {
+		if record.Line != idx+2 {
+			t.Errorf("Expected the records to be left in order but got %v", records)
+		}
+	}
+}
+
+func TestTrain_DatasetHash(t *testing.T) {
+	path := writeTrainConfig(t, `
+network:
+  layers: [1, 1]
+trainer:
+  max_iterations: 5
+  shuffle_rounds: 3
+  log_every: 1000
+data:
+  train: train.csv
+  split: 0.5
+  inputs:
+    - column: size
+  target:
+    columns: [weight]
+  impute: median
+`)
+	model := filepath.Join(filepath.Dir(path), "model.json")
+
+	hashes := []string{}
+	for run := 0; run < 2; run++ {
+		if err := train([]string{"-config", path}); err != nil {
+			t.Fatalf("Failed to train: %v", err)
+		}
+
+		pipeline, err := loadModel(model)
+		if err != nil {
+			t.Fatalf("Failed to load the trained model: %v", err)
+		}
+		hashes = append(hashes, pipeline.Network.Metadata.DatasetHash)
+	}
+
+	if hashes[0] == "" || hashes[0] != hashes[1] {
+		t.Errorf("Expected both runs to record the same dataset hash but got %v", hashes)
+	}
+}
@@ -0,0 +1,211 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package codegen generates standalone Go source for a trained network, so it
+// can be deployed without this library or a model file.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+// Generator writes a Go file that evaluates a network.  The file declares
+// every weight as a constant and a function, named FuncName, that takes an
+// array of inputs and returns an array of outputs, with every neuron unrolled
+// into its own expression.  Because the sizes are part of the array types, the
+// compiler checks every call and the function cannot fail.  The generated
+// function performs the same operations in the same order as Network.Process,
+// so it produces the same outputs.  Package defaults to "model" and FuncName to
+// "Predict"; the constants and helper are named after the function so several
+// networks can be generated into the same package.
+type Generator struct {
+	Package  string
+	FuncName string
+}
+
+// neuron is one unrolled neuron in the generated function.
+type neuron struct {
+	Name  string
+	Terms []string
+	Bias  string
+}
+
+type constant struct {
+	Name  string
+	Value string
+}
+
+type layerData struct {
+	Index     int
+	Constants []constant
+	Neurons   []neuron
+}
+
+type fileData struct {
+	Package string
+	Func    string
+	Prefix  string
+	Inputs  int
+	Outputs int
+	Layers  []layerData
+	Results []string
+}
+
+var source = template.Must(template.New("codegen").Parse(`// Code generated by gofeedforward codegen. DO NOT EDIT.
+
+package {{.Package}}
+
+import "math"
+
+// {{.Func}}Inputs is the number of inputs {{.Func}} takes.
+const {{.Func}}Inputs = {{.Inputs}}
+
+// {{.Func}}Outputs is the number of outputs {{.Func}} returns.
+const {{.Func}}Outputs = {{.Outputs}}
+{{range .Layers}}
+// The weights of layer {{.Index}}, named for the neuron and the input they
+// multiply, and the bias of each neuron.
+const (
+{{- range .Constants}}
+	{{.Name}} = {{.Value}}
+{{- end}}
+)
+{{end}}
+// {{.Func}} evaluates the network for the inputs and returns the outputs.
+func {{.Func}}(inputs [{{.Inputs}}]float64) [{{.Outputs}}]float64 {
+{{- range $idx, $layer := .Layers}}{{if $idx}}
+{{end}}{{range .Neurons}}
+	{{.Name}} := {{$.Prefix}}Sigmoid({{range .Terms}}{{.}} + {{end}}{{.Bias}})
+{{- end}}
+{{- end}}
+
+	return [{{.Outputs}}]float64{ {{- range $idx, $name := .Results}}{{if $idx}}, {{end}}{{$name}}{{end -}} }
+}
+
+func {{.Prefix}}Sigmoid(input float64) float64 {
+	return 1.0 / (1.0 + math.Exp(-input))
+}
+`))
+
+// Write writes the Go source for the network to w.
+func (g Generator) Write(w io.Writer, net gofeedforward.Network) error {
+	src, err := g.Source(net)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(src)
+	return err
+}
+
+// Source returns the formatted Go source for the network.
+func (g Generator) Source(net gofeedforward.Network) ([]byte, error) {
+	if err := net.Validate(); err != nil {
+		return nil, err
+	}
+
+	data := fileData{
+		Package: g.Package,
+		Func:    g.FuncName,
+		Inputs:  net.InputSize(),
+		Outputs: net.OutputSize(),
+	}
+	if data.Package == "" {
+		data.Package = "model"
+	}
+	if data.Func == "" {
+		data.Func = "Predict"
+	}
+
+	if !token.IsIdentifier(data.Package) || !token.IsIdentifier(data.Func) {
+		return nil, fmt.Errorf("Package %q and function %q must be Go identifiers", data.Package, data.Func)
+	}
+
+	first, size := utf8.DecodeRuneInString(data.Func)
+	data.Prefix = string(unicode.ToLower(first)) + data.Func[size:]
+
+	previous := []string{}
+	for i := 0; i < net.InputSize(); i++ {
+		previous = append(previous, fmt.Sprintf("inputs[%d]", i))
+	}
+
+	for l, layer := range net.Layers {
+		for _, weights := range layer.Weights {
+			for _, weight := range weights {
+				if math.IsNaN(weight) || math.IsInf(weight, 0) {
+					return nil, fmt.Errorf("Layer %d has an invalid weight %v", l, weight)
+				}
+			}
+		}
+
+		ld := layerData{Index: l}
+		current := []string{}
+		for n, weights := range layer.Weights {
+			name := fmt.Sprintf("%sL%dN%d", data.Prefix, l, n)
+			nr := neuron{Name: fmt.Sprintf("l%dn%d", l, n), Bias: name + "B"}
+			for i, input := range previous {
+				weight := fmt.Sprintf("%sW%d", name, i)
+				ld.Constants = append(ld.Constants, constant{Name: weight, Value: literal(weights[i])})
+				nr.Terms = append(nr.Terms, weight+"*"+input)
+			}
+			ld.Constants = append(ld.Constants, constant{Name: nr.Bias, Value: literal(weights[len(previous)])})
+			ld.Neurons = append(ld.Neurons, nr)
+			current = append(current, nr.Name)
+		}
+		data.Layers = append(data.Layers, ld)
+		previous = current
+	}
+	data.Results = previous
+
+	var buf bytes.Buffer
+	if err := source.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// literal formats a weight so that it parses back to exactly the same value.
+func literal(value float64) string {
+	text := strconv.FormatFloat(value, 'g', -1, 64)
+	if !strings.ContainsAny(text, ".e") {
+		text += ".0"
+	}
+	return text
+}
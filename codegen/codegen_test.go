@@ -0,0 +1,180 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+const driver = `package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"generated/model"
+)
+
+func main() {
+	inputs := %s
+	for _, input := range inputs {
+		for _, output := range model.Evaluate(input) {
+			fmt.Print(strconv.FormatFloat(output, 'g', -1, 64), " ")
+		}
+		fmt.Println()
+	}
+}
+`
+
+func TestGenerator_Compiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping compiling generated code in short mode")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("Skipping because the go command is not available")
+	}
+
+	net := gofeedforward.MakeNetwork(4, 5, 3, 2)
+	net.Randomize()
+	net.Layers[0].Weights[0][0] = 2.0
+	net.Layers[1].Weights[1][2] = -1e-20
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "model"), 0755); err != nil {
+		t.Fatalf("Failed to create the package directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module generated\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatalf("Failed to write go.mod: %v", err)
+	}
+
+	file, err := os.Create(filepath.Join(dir, "model", "model.go"))
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := (Generator{FuncName: "Evaluate"}).Write(file, net); err != nil {
+		t.Fatalf("Failed to generate source: %v", err)
+	}
+	file.Close()
+
+	inputs := [][]float64{}
+	literal := "[][4]float64{"
+	for i := 0; i < 10; i++ {
+		input := []float64{rand.Float64(), rand.Float64() * 10, -rand.Float64(), float64(i)}
+		inputs = append(inputs, input)
+		literal += fmt.Sprintf("{%v, %v, %v, %v}, ", input[0], input[1], input[2], input[3])
+	}
+	literal += "}"
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(fmt.Sprintf(driver, literal)), 0644); err != nil {
+		t.Fatalf("Failed to write main.go: %v", err)
+	}
+
+	cmd := exec.Command(gobin, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Failed to run generated code: %v\n%s", err, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != len(inputs) {
+		t.Fatalf("Expected %d lines but got %d: %s", len(inputs), len(lines), out)
+	}
+
+	for idx, input := range inputs {
+		expected, _ := net.Process(input)
+		fields := strings.Fields(lines[idx])
+		if len(fields) != len(expected) {
+			t.Fatalf("Expected %d outputs but got %q", len(expected), lines[idx])
+		}
+
+		for col, field := range fields {
+			actual, err := strconv.ParseFloat(field, 64)
+			if err != nil || actual != expected[col] {
+				t.Errorf("Expected output %d of input %d to be %v but got %s", col, idx, expected[col], field)
+			}
+		}
+	}
+}
+
+func TestGenerator_Source(t *testing.T) {
+	net := gofeedforward.MakeNetwork(2, 1)
+	net.Layers[0].Weights[0] = []float64{1.0, -0.5, 3.0}
+
+	src, err := Generator{Package: "scoring"}.Source(net)
+	if err != nil {
+		t.Fatalf("Failed to generate source: %v", err)
+	}
+
+	for _, expected := range []string{
+		"package scoring",
+		"DO NOT EDIT.",
+		"const PredictInputs = 2",
+		"predictL0N0W0 = 1.0",
+		"predictL0N0W1 = -0.5",
+		"predictL0N0B  = 3.0",
+		"func Predict(inputs [2]float64) [1]float64",
+		"predictSigmoid(predictL0N0W0*inputs[0] + predictL0N0W1*inputs[1] + predictL0N0B)",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("Expected the source to contain %q:\n%s", expected, src)
+		}
+	}
+}
+
+func TestGenerator_Errors(t *testing.T) {
+	net := gofeedforward.MakeNetwork(2, 1)
+
+	if _, err := (Generator{FuncName: "not valid"}).Source(net); err == nil {
+		t.Error("Expected an error for a function name that is not an identifier")
+	}
+
+	if _, err := (Generator{}).Source(gofeedforward.Network{}); err == nil {
+		t.Error("Expected an error for a network without layers")
+	}
+
+	net.Layers[0].Weights[0][1] = math.NaN()
+	if _, err := (Generator{}).Source(net); err == nil {
+		t.Error("Expected an error for a NaN weight")
+	}
+}
@@ -0,0 +1,369 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MissingPolicy determines what a CSVLoader does when it reads a missing value.
+type MissingPolicy int
+
+const (
+	// MissingIsError stops loading with an error that identifies the missing
+	// value.  This is the default.
+	MissingIsError MissingPolicy = iota
+
+	// MissingSkipsRow quietly skips any row with a missing value.
+	MissingSkipsRow
+
+	// MissingIsNaN stores a missing value as NaN so that it can be dealt with
+	// after loading.
+	MissingIsNaN
+)
+
+// DefaultMissingValues are the values treated as missing when a CSVLoader does
+// not list its own.  The comparison ignores case and surrounding spaces.
+var DefaultMissingValues = []string{"", "NA", "N/A", "NaN", "?"}
+
+// Column identifies a column in a CSV file either by its name in the header or
+// by its zero based index.  Use ColumnNamed or ColumnAt to create one.
+type Column struct {
+	Name  string
+	Index int
+}
+
+// ColumnNamed identifies a column by its name in the header row.
+func ColumnNamed(name string) Column {
+	return Column{Name: name, Index: -1}
+}
+
+// ColumnAt identifies a column by its zero based position in a row.
+func ColumnAt(index int) Column {
+	return Column{Index: index}
+}
+
+func (c Column) String() string {
+	if c.Index < 0 {
+		return fmt.Sprintf("%q", c.Name)
+	}
+	return fmt.Sprintf("%d", c.Index)
+}
+
+// CSVLoader describes how to turn the rows of a CSV file into training data.
+// Inputs and Expected list the columns, in order, that make up the inputs and
+// expected values of each TrainingDatum; any other column is ignored.  Header
+// indicates the first row holds column names, which is required to use named
+// columns.  Comma is the field delimiter and defaults to a comma.
+type CSVLoader struct {
+	Header        bool
+	Inputs        []Column
+	Expected      []Column
+	Comma         rune
+	Missing       MissingPolicy
+	MissingValues []string
+}
+
+// CSVError reports a value that could not be loaded.  Line and Column are the
+// one based position of the value in the file and Name is the column name if
+// the file has a header.
+type CSVError struct {
+	Line   int
+	Column int
+	Name   string
+	Err    error
+}
+
+func (e *CSVError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("line %d, column %d (%s): %v", e.Line, e.Column, e.Name, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *CSVError) Unwrap() error {
+	return e.Err
+}
+
+// CSVReader reads training data from a CSV file one row at a time, so that
+// files larger than memory can be processed.  It is created by CSVLoader.Open.
+type CSVReader struct {
+	loader   CSVLoader
+	reader   *csv.Reader
+	names    []string
+	inputs   []int
+	expected []int
+	started  bool
+}
+
+// Open returns a reader that produces a TrainingDatum for each row read from r.
+func (l CSVLoader) Open(r io.Reader) *CSVReader {
+	reader := csv.NewReader(r)
+	if l.Comma != 0 {
+		reader.Comma = l.Comma
+	}
+	reader.ReuseRecord = true
+	return &CSVReader{loader: l, reader: reader}
+}
+
+// Load reads every row from r and returns the training data.
+func (l CSVLoader) Load(r io.Reader) (TrainingData, error) {
+	reader := l.Open(r)
+	result := TrainingData{}
+	for {
+		datum, err := reader.Read()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, datum)
+	}
+}
+
+// CSVRecord is a row read without parsing its values, for data with
+// categories or other fields that are encoded by a Pipeline.  Line is the one
+// based line the row starts on, Row holds every field of the row and Inputs and
+// Expected hold the fields of the loader's input and expected columns, in
+// order.
+type CSVRecord struct {
+	Line     int
+	Row      []string
+	Inputs   []string
+	Expected []string
+}
+
+// LoadRecords reads every row from r without parsing the values.  It returns
+// the header, if the loader has one, and the records.
+func (l CSVLoader) LoadRecords(r io.Reader) ([]string, []CSVRecord, error) {
+	reader := l.Open(r)
+	result := []CSVRecord{}
+	for {
+		record, err := reader.ReadRecord()
+		if err == io.EOF {
+			return reader.Header(), result, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, record)
+	}
+}
+
+// Header returns the column names from the header row, or nil if the loader
+// has no header or nothing has been read yet.
+func (r *CSVReader) Header() []string {
+	return r.names
+}
+
+// ReadRecord returns the next row without parsing it.  Missing values are
+// returned as they are, whatever the loader's missing value policy, since
+// whether a value is missing depends on how it is encoded.  It returns io.EOF
+// when there are no more rows.
+func (r *CSVReader) ReadRecord() (CSVRecord, error) {
+	if !r.started {
+		if err := r.start(); err != nil {
+			return CSVRecord{}, err
+		}
+	}
+
+	row, err := r.reader.Read()
+	if err != nil {
+		return CSVRecord{}, err
+	}
+
+	line, _ := r.reader.FieldPos(0)
+	result := CSVRecord{Line: line, Row: append([]string{}, row...)}
+	if result.Inputs, err = r.fields(row, r.inputs); err != nil {
+		return CSVRecord{}, err
+	}
+	if result.Expected, err = r.fields(row, r.expected); err != nil {
+		return CSVRecord{}, err
+	}
+	return result, nil
+}
+
+// fields returns the fields at the given positions.
+func (r *CSVReader) fields(record []string, positions []int) ([]string, error) {
+	result := make([]string, len(positions))
+	for idx, pos := range positions {
+		if pos >= len(record) {
+			line, _ := r.reader.FieldPos(len(record) - 1)
+			return nil, r.error(line, pos, fmt.Errorf("row has only %d columns", len(record)))
+		}
+		result[idx] = record[pos]
+	}
+	return result, nil
+}
+
+// Read returns the next TrainingDatum.  It returns io.EOF when there are no
+// more rows.  Rows with missing values are skipped if the loader's policy is
+// MissingSkipsRow.
+func (r *CSVReader) Read() (TrainingDatum, error) {
+	if !r.started {
+		if err := r.start(); err != nil {
+			return TrainingDatum{}, err
+		}
+	}
+
+	for {
+		record, err := r.reader.Read()
+		if err != nil {
+			return TrainingDatum{}, err
+		}
+
+		datum := TrainingDatum{Inputs: make([]float64, len(r.inputs)), Expected: make([]float64, len(r.expected))}
+		skip, err := r.parse(record, r.inputs, datum.Inputs)
+		if err == nil && !skip {
+			skip, err = r.parse(record, r.expected, datum.Expected)
+		}
+
+		if err != nil {
+			return TrainingDatum{}, err
+		}
+
+		if !skip {
+			return datum, nil
+		}
+	}
+}
+
+// start reads the header, if there is one, and resolves the columns to
+// positions in each row.
+func (r *CSVReader) start() error {
+	r.started = true
+	if r.loader.Header {
+		header, err := r.reader.Read()
+		if err != nil {
+			return err
+		}
+		r.names = make([]string, len(header))
+		for idx := range header {
+			r.names[idx] = strings.TrimSpace(header[idx])
+		}
+	}
+
+	var err error
+	if r.inputs, err = r.resolve(r.loader.Inputs); err != nil {
+		return err
+	}
+	r.expected, err = r.resolve(r.loader.Expected)
+	return err
+}
+
+func (r *CSVReader) resolve(columns []Column) ([]int, error) {
+	result := make([]int, len(columns))
+	for idx, column := range columns {
+		if column.Index >= 0 {
+			if r.names != nil && column.Index >= len(r.names) {
+				return nil, fmt.Errorf("Column %d is beyond the %d columns in the header", column.Index, len(r.names))
+			}
+			result[idx] = column.Index
+			continue
+		}
+
+		if r.names == nil {
+			return nil, fmt.Errorf("Column %s can only be found in a file with a header", column)
+		}
+
+		result[idx] = -1
+		for pos, name := range r.names {
+			if name == column.Name {
+				result[idx] = pos
+				break
+			}
+		}
+
+		if result[idx] < 0 {
+			return nil, fmt.Errorf("Column %s is not in the header", column)
+		}
+	}
+	return result, nil
+}
+
+// parse converts the fields at the given positions into values.  It returns
+// true if the row should be skipped because of a missing value.
+func (r *CSVReader) parse(record []string, positions []int, values []float64) (bool, error) {
+	for idx, pos := range positions {
+		if pos >= len(record) {
+			line, _ := r.reader.FieldPos(len(record) - 1)
+			return false, r.error(line, pos, fmt.Errorf("row has only %d columns", len(record)))
+		}
+
+		field := strings.TrimSpace(record[pos])
+		if r.isMissing(field) {
+			switch r.loader.Missing {
+			case MissingSkipsRow:
+				return true, nil
+			case MissingIsNaN:
+				values[idx] = math.NaN()
+				continue
+			default:
+				line, _ := r.reader.FieldPos(pos)
+				return false, r.error(line, pos, fmt.Errorf("missing value %q", field))
+			}
+		}
+
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			line, _ := r.reader.FieldPos(pos)
+			return false, r.error(line, pos, fmt.Errorf("%q is not a number", field))
+		}
+		values[idx] = value
+	}
+	return false, nil
+}
+
+func (r *CSVReader) isMissing(field string) bool {
+	missing := r.loader.MissingValues
+	if missing == nil {
+		missing = DefaultMissingValues
+	}
+
+	for _, value := range missing {
+		if strings.EqualFold(field, strings.TrimSpace(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *CSVReader) error(line, pos int, err error) error {
+	result := &CSVError{Line: line, Column: pos + 1, Err: err}
+	if pos < len(r.names) {
+		result.Name = r.names[pos]
+	}
+	return result
+}
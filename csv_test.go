@@ -0,0 +1,162 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+const irisCSV = `sepal_length,sepal_width,petal_length,petal_width,"setosa, score",other
+5.1,3.5,1.4,0.2,0.9,0.1
+6.3,2.5,5.0,1.9,0.1,0.9
+`
+
+func TestCSVLoader_LoadNamed(t *testing.T) {
+	loader := CSVLoader{
+		Header:   true,
+		Inputs:   []Column{ColumnNamed("petal_width"), ColumnNamed("sepal_length")},
+		Expected: []Column{ColumnNamed("setosa, score")},
+	}
+
+	td, err := loader.Load(strings.NewReader(irisCSV))
+	if err != nil {
+		t.Errorf("Failed to load CSV: %v", err)
+	}
+
+	if len(td) != 2 {
+		t.Errorf("Expected 2 rows but got %d", len(td))
+	}
+
+	if outOfBoundsCheck(1.9, td[1].Inputs[0], 0.001) || outOfBoundsCheck(6.3, td[1].Inputs[1], 0.001) ||
+		outOfBoundsCheck(0.1, td[1].Expected[0], 0.001) {
+		t.Errorf("Unexpected values %v", td[1])
+	}
+}
+
+func TestCSVLoader_LoadIndexed(t *testing.T) {
+	loader := CSVLoader{Comma: ';', Inputs: []Column{ColumnAt(0), ColumnAt(1)}, Expected: []Column{ColumnAt(2)}}
+
+	td, err := loader.Load(strings.NewReader("1;0;0.9\n0; 0 ;0.1\n"))
+	if err != nil {
+		t.Errorf("Failed to load CSV: %v", err)
+	}
+
+	if len(td) != 2 || outOfBoundsCheck(0.1, td[1].Expected[0], 0.001) {
+		t.Errorf("Unexpected training data %v", td)
+	}
+
+	if _, err := (CSVLoader{Inputs: []Column{ColumnNamed("x")}}).Load(strings.NewReader("1\n")); err == nil {
+		t.Error("Expected an error for a named column without a header")
+	}
+}
+
+func TestCSVLoader_TypeError(t *testing.T) {
+	loader := CSVLoader{Header: true, Inputs: []Column{ColumnNamed("a"), ColumnNamed("b")}}
+
+	_, err := loader.Load(strings.NewReader("a,b\n1,2\n3,four\n"))
+	var csvErr *CSVError
+	if !errors.As(err, &csvErr) {
+		t.Fatalf("Expected a CSVError but got %v", err)
+	}
+
+	if csvErr.Line != 3 || csvErr.Column != 2 || csvErr.Name != "b" {
+		t.Errorf("Expected line 3, column 2 (b) but got %v", csvErr)
+	}
+}
+
+func TestCSVLoader_Missing(t *testing.T) {
+	data := "a,b\n1,2\nNA,3\n4,\n5,6\n"
+	loader := CSVLoader{Header: true, Inputs: []Column{ColumnNamed("a")}, Expected: []Column{ColumnNamed("b")}}
+
+	if _, err := loader.Load(strings.NewReader(data)); err == nil {
+		t.Error("Expected an error for a missing value")
+	}
+
+	loader.Missing = MissingSkipsRow
+	td, _ := loader.Load(strings.NewReader(data))
+	if len(td) != 2 || td[1].Inputs[0] != 5.0 {
+		t.Errorf("Expected rows with missing values to be skipped but got %v", td)
+	}
+
+	loader.Missing = MissingIsNaN
+	td, _ = loader.Load(strings.NewReader(data))
+	if len(td) != 4 || !math.IsNaN(td[1].Inputs[0]) || !math.IsNaN(td[2].Expected[0]) {
+		t.Errorf("Expected missing values to be NaN but got %v", td)
+	}
+}
+
+func TestCSVReader_Read(t *testing.T) {
+	reader := CSVLoader{Inputs: []Column{ColumnAt(0)}}.Open(strings.NewReader("1\n2\n"))
+
+	for _, expected := range []float64{1.0, 2.0} {
+		datum, err := reader.Read()
+		if err != nil || datum.Inputs[0] != expected {
+			t.Errorf("Expected %0.1f but got %v, %v", expected, datum, err)
+		}
+	}
+
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("Expected io.EOF but got %v", err)
+	}
+}
+
+func TestCSVLoader_LoadRecords(t *testing.T) {
+	loader := CSVLoader{
+		Header:   true,
+		Inputs:   []Column{ColumnNamed("color"), ColumnAt(0)},
+		Expected: []Column{ColumnNamed("label")},
+	}
+
+	header, records, err := loader.LoadRecords(strings.NewReader("size,color,label\n1,red,hot\nNA,blue,cold\n"))
+	if err != nil {
+		t.Fatalf("Failed to load records: %v", err)
+	}
+
+	if strings.Join(header, ",") != "size,color,label" || len(records) != 2 {
+		t.Fatalf("Unexpected header %v and records %v", header, records)
+	}
+
+	second := records[1]
+	if second.Line != 3 || strings.Join(second.Row, ",") != "NA,blue,cold" ||
+		strings.Join(second.Inputs, ",") != "blue,NA" || strings.Join(second.Expected, ",") != "cold" {
+		t.Errorf("Unexpected record %v", second)
+	}
+
+	if _, _, err := loader.LoadRecords(strings.NewReader("size,color,label\n1,red\n")); err == nil {
+		t.Error("Expected an error for a short row")
+	}
+
+	if _, _, err := loader.LoadRecords(strings.NewReader("size,colour,label\n1,red,hot\n")); err == nil {
+		t.Error("Expected an error for a missing column")
+	}
+}
@@ -0,0 +1,197 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// CurvePoint is a single point on a threshold curve.  An example is classified
+// as positive when the network output is greater than the threshold, which is
+// the same rule used by MakeThresholdClassifier.  The true positive rate is
+// also the recall.
+type CurvePoint struct {
+	Threshold         float64
+	TruePositiveRate  float64
+	FalsePositiveRate float64
+	Precision         float64
+}
+
+// ThresholdCurve is the result of sweeping the classification threshold over
+// a single network output.  The points are ordered from the highest threshold,
+// where nothing is classified as positive, to the lowest, where everything is.
+// The same points make up both the ROC curve (false positive rate against true
+// positive rate) and the precision-recall curve.
+type ThresholdCurve struct {
+	Points           []CurvePoint
+	AUC              float64
+	AveragePrecision float64
+}
+
+// F1 returns the harmonic mean of the precision and recall at this point.
+func (p CurvePoint) F1() float64 {
+	if p.Precision+p.TruePositiveRate == 0 {
+		return 0.0
+	}
+	return 2 * p.Precision * p.TruePositiveRate / (p.Precision + p.TruePositiveRate)
+}
+
+// YoudenJ returns Youden's J statistic, the true positive rate minus the false
+// positive rate, at this point.
+func (p CurvePoint) YoudenJ() float64 {
+	return p.TruePositiveRate - p.FalsePositiveRate
+}
+
+// MakeThresholdCurves runs the network over each example in the data and
+// produces a threshold curve for every network output.  An example is a
+// positive example for an output when its expected value is greater than the
+// label threshold, so data encoded with 0.1 and 0.9 would use a label threshold
+// of 0.5.  Every output must have at least one positive and one negative
+// example.
+func MakeThresholdCurves(net Network, td TrainingData, labelThreshold float64) ([]ThresholdCurve, error) {
+	if len(td) == 0 {
+		return nil, fmt.Errorf("Unable to make threshold curves without data")
+	}
+
+	scores := make([][]float64, net.OutputSize())
+	for _, datum := range td {
+		outputs, err := net.Process(datum.Inputs)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(datum.Expected) != len(outputs) {
+			return nil, fmt.Errorf("Failed to processes data with length %d against expected output of length %d",
+				len(datum.Expected), len(outputs))
+		}
+
+		for idx := range outputs {
+			scores[idx] = append(scores[idx], outputs[idx])
+		}
+	}
+
+	result := make([]ThresholdCurve, net.OutputSize())
+	for output := range result {
+		positives := make([]bool, len(td))
+		for idx, datum := range td {
+			positives[idx] = datum.Expected[output] > labelThreshold
+		}
+
+		curve, err := makeThresholdCurve(scores[output], positives)
+		if err != nil {
+			return nil, fmt.Errorf("Output %d: %v", output, err)
+		}
+		result[output] = curve
+	}
+	return result, nil
+}
+
+func makeThresholdCurve(scores []float64, positives []bool) (ThresholdCurve, error) {
+	order := make([]int, len(scores))
+	totalPositive := 0
+	for idx := range order {
+		order[idx] = idx
+		if positives[idx] {
+			totalPositive++
+		}
+	}
+	totalNegative := len(scores) - totalPositive
+
+	if totalPositive == 0 || totalNegative == 0 {
+		return ThresholdCurve{}, fmt.Errorf("Threshold curves need positive and negative examples but got %d and %d",
+			totalPositive, totalNegative)
+	}
+
+	sort.Slice(order, func(i, j int) bool { return scores[order[i]] > scores[order[j]] })
+
+	curve := ThresholdCurve{Points: []CurvePoint{{Threshold: scores[order[0]], Precision: 1.0}}}
+	truePositives, falsePositives := 0, 0
+	for idx := 0; idx < len(order); idx++ {
+		if positives[order[idx]] {
+			truePositives++
+		} else {
+			falsePositives++
+		}
+
+		// Examples with the same score are all on the same side of any threshold.
+		if idx+1 < len(order) && scores[order[idx+1]] == scores[order[idx]] {
+			continue
+		}
+
+		threshold := math.Nextafter(scores[order[idx]], math.Inf(-1))
+		if idx+1 < len(order) {
+			threshold = (scores[order[idx]] + scores[order[idx+1]]) / 2
+		}
+
+		point := CurvePoint{
+			Threshold:         threshold,
+			TruePositiveRate:  float64(truePositives) / float64(totalPositive),
+			FalsePositiveRate: float64(falsePositives) / float64(totalNegative),
+			Precision:         float64(truePositives) / float64(truePositives+falsePositives),
+		}
+
+		previous := curve.Points[len(curve.Points)-1]
+		curve.AUC += (point.FalsePositiveRate - previous.FalsePositiveRate) *
+			(point.TruePositiveRate + previous.TruePositiveRate) / 2
+		curve.AveragePrecision += (point.TruePositiveRate - previous.TruePositiveRate) * point.Precision
+		curve.Points = append(curve.Points, point)
+	}
+
+	return curve, nil
+}
+
+// BestF1 returns the point on the curve with the highest F1 score.  Its
+// threshold is the recommended threshold when precision and recall are equally
+// important.  It is an error if the curve has no points.
+func (c ThresholdCurve) BestF1() (CurvePoint, error) {
+	return c.best(CurvePoint.F1)
+}
+
+// BestYoudenJ returns the point on the curve with the highest Youden's J
+// statistic, which is the point of the ROC curve farthest above the diagonal.
+// It is an error if the curve has no points.
+func (c ThresholdCurve) BestYoudenJ() (CurvePoint, error) {
+	return c.best(CurvePoint.YoudenJ)
+}
+
+func (c ThresholdCurve) best(score func(CurvePoint) float64) (CurvePoint, error) {
+	if len(c.Points) == 0 {
+		return CurvePoint{}, fmt.Errorf("Threshold curve has no points")
+	}
+
+	best := c.Points[0]
+	for _, point := range c.Points[1:] {
+		if score(point) > score(best) {
+			best = point
+		}
+	}
+	return best, nil
+}
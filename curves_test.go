@@ -0,0 +1,118 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import "testing"
+
+func curveNetwork() Network {
+	net := MakeNetwork(1, 1)
+	net.Layers[0].Weights[0] = []float64{1.0, 0.0}
+	return net
+}
+
+func TestMakeThresholdCurves(t *testing.T) {
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{4.0}, Expected: []float64{0.9}},
+		TrainingDatum{Inputs: []float64{3.0}, Expected: []float64{0.9}},
+		TrainingDatum{Inputs: []float64{2.0}, Expected: []float64{0.1}},
+		TrainingDatum{Inputs: []float64{1.0}, Expected: []float64{0.9}},
+		TrainingDatum{Inputs: []float64{0.0}, Expected: []float64{0.1}},
+	}
+
+	curves, err := MakeThresholdCurves(curveNetwork(), td, 0.5)
+	if err != nil {
+		t.Errorf("Failed to make curves: %v", err)
+	}
+
+	if len(curves) != 1 || len(curves[0].Points) != 6 {
+		t.Errorf("Expected one curve with 6 points but got %v", curves)
+	}
+
+	curve := curves[0]
+	if outOfBoundsCheck(5.0/6.0, curve.AUC, 0.0001) {
+		t.Errorf("Expected AUC of 0.8333 but got %0.4f", curve.AUC)
+	}
+
+	if outOfBoundsCheck((1.0+1.0+0.75)/3.0, curve.AveragePrecision, 0.0001) {
+		t.Errorf("Expected average precision of 0.9167 but got %0.4f", curve.AveragePrecision)
+	}
+
+	last := curve.Points[len(curve.Points)-1]
+	if outOfBoundsCheck(1.0, last.TruePositiveRate, 0.0001) || outOfBoundsCheck(1.0, last.FalsePositiveRate, 0.0001) {
+		t.Errorf("The last point should classify everything as positive: %+v", last)
+	}
+}
+
+func TestThresholdCurve_Best(t *testing.T) {
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{4.0}, Expected: []float64{0.9}},
+		TrainingDatum{Inputs: []float64{3.0}, Expected: []float64{0.9}},
+		TrainingDatum{Inputs: []float64{2.0}, Expected: []float64{0.1}},
+		TrainingDatum{Inputs: []float64{1.0}, Expected: []float64{0.1}},
+	}
+	net := curveNetwork()
+	curves, _ := MakeThresholdCurves(net, td, 0.5)
+
+	best, err := curves[0].BestF1()
+	if err != nil {
+		t.Fatalf("Failed to find the best F1: %v", err)
+	}
+
+	if outOfBoundsCheck(1.0, best.F1(), 0.0001) || outOfBoundsCheck(1.0, best.YoudenJ(), 0.0001) {
+		t.Errorf("Expected a perfect threshold but got %+v", best)
+	}
+
+	best, err = curves[0].BestYoudenJ()
+	if err != nil {
+		t.Fatalf("Failed to find the best J: %v", err)
+	}
+
+	classifier := MakeThresholdClassifier([]string{"positive"}, best.Threshold)
+	for _, datum := range td {
+		classes, _ := net.Classify(datum.Inputs, classifier)
+		if (len(classes) == 1) != (datum.Expected[0] > 0.5) {
+			t.Errorf("The recommended threshold misclassified %v", datum.Inputs)
+		}
+	}
+
+	if _, err := (ThresholdCurve{}).BestF1(); err == nil {
+		t.Error("Expected an error for a curve without points")
+	}
+}
+
+func TestMakeThresholdCurvesOneClass(t *testing.T) {
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{4.0}, Expected: []float64{0.9}},
+		TrainingDatum{Inputs: []float64{3.0}, Expected: []float64{0.9}},
+	}
+
+	if _, err := MakeThresholdCurves(curveNetwork(), td, 0.5); err == nil {
+		t.Error("Expected an error when there are no negative examples")
+	}
+}
@@ -0,0 +1,216 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+)
+
+// Encoder turns a categorical value, such as a species name, into a vector of
+// values that can be presented to a network as inputs or used as expected
+// outputs.  Size is the length of every vector the encoder produces.
+type Encoder interface {
+	Encode(value string) ([]float64, error)
+	Size() int
+}
+
+// OneHotEncoder encodes a category as a vector with one element per category.
+// The element for the category is set to On and every other element is set to
+// Off.  Because the sigmoid never reaches 0.0 or 1.0, expected values are
+// usually encoded with an On of 0.9 and an Off of 0.1.
+type OneHotEncoder struct {
+	Categories []string
+	On         float64
+	Off        float64
+}
+
+// OrdinalEncoder encodes a category as a single value, its position in the
+// list of categories.  It is best suited to categories with a natural order.
+type OrdinalEncoder struct {
+	Categories []string
+}
+
+// HashEncoder encodes a category by hashing it into one of a fixed number of
+// buckets and setting that bucket to On and the others to Off.  It does not
+// need to be fit, so it can encode categories it has never seen, but
+// different categories may share a bucket and it cannot be decoded.
+type HashEncoder struct {
+	Buckets int
+	On      float64
+	Off     float64
+}
+
+// categories returns the distinct values in sorted order.
+func categories(values []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func categoryIndex(categories []string, value string) (int, error) {
+	for idx, category := range categories {
+		if category == value {
+			return idx, nil
+		}
+	}
+	return -1, fmt.Errorf("Unknown category %q", value)
+}
+
+// FitOneHotEncoder learns the categories, in sorted order, from a column of
+// values.
+func FitOneHotEncoder(values []string, on, off float64) OneHotEncoder {
+	return OneHotEncoder{Categories: categories(values), On: on, Off: off}
+}
+
+// Encode returns the one-hot vector for a category.  It is an error to encode
+// a category the encoder was not fit with.
+func (e OneHotEncoder) Encode(value string) ([]float64, error) {
+	idx, err := categoryIndex(e.Categories, value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]float64, len(e.Categories))
+	for i := range result {
+		result[i] = e.Off
+	}
+	result[idx] = e.On
+	return result, nil
+}
+
+// Size returns the number of categories.
+func (e OneHotEncoder) Size() int {
+	return len(e.Categories)
+}
+
+// Decode returns the category for the highest value in the network outputs.
+func (e OneHotEncoder) Decode(outputs []float64) (string, error) {
+	classes, err := e.Classifier()(outputs)
+	if err != nil {
+		return "", err
+	}
+	return classes[0], nil
+}
+
+// Classifier returns a best of classifier over the encoder's categories, so the
+// outputs of a network trained on one-hot encoded expected values can be
+// classified with Classify or ClassificationError.
+func (e OneHotEncoder) Classifier() BasicClassifier {
+	return MakeBestOfClassifier(e.Categories)
+}
+
+// FitOrdinalEncoder learns the categories, in sorted order, from a column of
+// values.  Create the encoder directly to use some other order.
+func FitOrdinalEncoder(values []string) OrdinalEncoder {
+	return OrdinalEncoder{Categories: categories(values)}
+}
+
+// Encode returns the position of the category.  It is an error to encode a
+// category the encoder was not fit with.
+func (e OrdinalEncoder) Encode(value string) ([]float64, error) {
+	idx, err := categoryIndex(e.Categories, value)
+	if err != nil {
+		return nil, err
+	}
+	return []float64{float64(idx)}, nil
+}
+
+// Size always returns 1.
+func (e OrdinalEncoder) Size() int {
+	return 1
+}
+
+// Decode returns the category nearest to the single output value.
+func (e OrdinalEncoder) Decode(outputs []float64) (string, error) {
+	classes, err := e.Classifier()(outputs)
+	if err != nil {
+		return "", err
+	}
+	return classes[0], nil
+}
+
+// Classifier returns a classifier that rounds a single output to the nearest
+// category position.
+func (e OrdinalEncoder) Classifier() BasicClassifier {
+	return func(rawValues []float64) ([]string, error) {
+		if len(rawValues) != 1 {
+			return nil, fmt.Errorf("Unable to classify because there are %d outputs instead of 1", len(rawValues))
+		}
+
+		if len(e.Categories) == 0 {
+			return nil, fmt.Errorf("Unable to classify without categories")
+		}
+
+		idx := int(math.Round(rawValues[0]))
+		if idx < 0 {
+			idx = 0
+		}
+		if idx >= len(e.Categories) {
+			idx = len(e.Categories) - 1
+		}
+		return []string{e.Categories[idx]}, nil
+	}
+}
+
+// MakeHashEncoder creates an encoder with the given number of buckets.
+func MakeHashEncoder(buckets int, on, off float64) HashEncoder {
+	return HashEncoder{Buckets: buckets, On: on, Off: off}
+}
+
+// Encode returns the vector for the bucket the category hashes to.
+func (e HashEncoder) Encode(value string) ([]float64, error) {
+	if e.Buckets < 1 {
+		return nil, fmt.Errorf("Unable to hash into %d buckets", e.Buckets)
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(value))
+
+	result := make([]float64, e.Buckets)
+	for i := range result {
+		result[i] = e.Off
+	}
+	result[h.Sum32()%uint32(e.Buckets)] = e.On
+	return result, nil
+}
+
+// Size returns the number of buckets.
+func (e HashEncoder) Size() int {
+	return e.Buckets
+}
@@ -0,0 +1,114 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import "testing"
+
+var species = []string{"setosa", "virginica", "setosa", "versicolor"}
+
+func TestOneHotEncoder(t *testing.T) {
+	encoder := FitOneHotEncoder(species, 0.9, 0.1)
+
+	if encoder.Size() != 3 || encoder.Categories[0] != "setosa" || encoder.Categories[2] != "virginica" {
+		t.Errorf("Unexpected categories %v", encoder.Categories)
+	}
+
+	encoded, err := encoder.Encode("versicolor")
+	if err != nil {
+		t.Errorf("Failed to encode: %v", err)
+	}
+
+	if outOfBoundsCheck(0.1, encoded[0], 0.001) || outOfBoundsCheck(0.9, encoded[1], 0.001) || outOfBoundsCheck(0.1, encoded[2], 0.001) {
+		t.Errorf("Expected [0.1 0.9 0.1] but got %v", encoded)
+	}
+
+	if _, err := encoder.Encode("daisy"); err == nil {
+		t.Error("Expected an error for an unknown category")
+	}
+
+	if decoded, _ := encoder.Decode([]float64{0.2, 0.3, 0.7}); decoded != "virginica" {
+		t.Errorf("Expected 'virginica' but got '%s'", decoded)
+	}
+}
+
+func TestOneHotEncoder_Classifier(t *testing.T) {
+	encoder := FitOneHotEncoder(species, 0.9, 0.1)
+
+	td := TrainingData{}
+	for _, s := range species {
+		expected, _ := encoder.Encode(s)
+		td = append(td, TrainingDatum{Inputs: []float64{0.0}, Expected: expected})
+	}
+
+	// An untrained network with zero weights produces the same value for every
+	// output, so everything is classified as the first category.
+	classError, err := ClassificationError(MakeNetwork(1, 3), td, encoder.Classifier())
+	if err != nil || outOfBoundsCheck(0.5, classError, 0.001) {
+		t.Errorf("Expected a classification error of 0.5 but got %0.4f, %v", classError, err)
+	}
+}
+
+func TestOrdinalEncoder(t *testing.T) {
+	encoder := FitOrdinalEncoder(species)
+
+	encoded, _ := encoder.Encode("virginica")
+	if encoder.Size() != 1 || outOfBoundsCheck(2.0, encoded[0], 0.001) {
+		t.Errorf("Expected [2] but got %v", encoded)
+	}
+
+	if decoded, _ := encoder.Decode([]float64{1.2}); decoded != "versicolor" {
+		t.Errorf("Expected 'versicolor' but got '%s'", decoded)
+	}
+
+	if decoded, _ := encoder.Decode([]float64{7.0}); decoded != "virginica" {
+		t.Errorf("Expected 'virginica' but got '%s'", decoded)
+	}
+}
+
+func TestHashEncoder(t *testing.T) {
+	encoder := MakeHashEncoder(8, 1.0, 0.0)
+
+	first, _ := encoder.Encode("setosa")
+	second, _ := encoder.Encode("setosa")
+	if len(first) != 8 {
+		t.Errorf("Expected 8 buckets but got %d", len(first))
+	}
+
+	total := 0.0
+	for idx := range first {
+		total += first[idx]
+		if first[idx] != second[idx] {
+			t.Errorf("The same category should always hash to the same bucket")
+		}
+	}
+
+	if outOfBoundsCheck(1.0, total, 0.001) {
+		t.Errorf("Expected exactly one bucket to be set but got %v", first)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// HistoryEntry records a single training iteration.  Loss is the trainer's loss
+// for the training data and Errors is the mean squared error for each output.
+// The validation fields are only filled in when the history has validation
+// data; ValidationErrors is nil otherwise.  Seconds is the wall time since
+// training started.
+type HistoryEntry struct {
+	Iteration        int
+	Loss             float64
+	Errors           SquaredError
+	ValidationLoss   float64
+	ValidationErrors SquaredError
+	Alpha            float64
+	GradientNorm     float64
+	Seconds          float64
+}
+
+// History collects a HistoryEntry for every training iteration.  It is created
+// with Trainer.AddHistory.
+type History struct {
+	Entries    []HistoryEntry
+	net        *Network
+	validation TrainingData
+	start      time.Time
+}
+
+// AddHistory registers callbacks that record the history of the training.  If
+// validation data is given, the network is evaluated against it at the end of
+// every iteration, which is useful for spotting overfitting but adds to the
+// time each iteration takes.  The network must be the one being trained.
+func (t *Trainer) AddHistory(net *Network, validation TrainingData) *History {
+	history := &History{net: net, validation: validation, start: time.Now()}
+
+	t.AddTrainingBeginHandler(func(t *Trainer) {
+		history.start = time.Now()
+	})
+
+	t.AddIterationEndHandler(func(t *Trainer, mse SquaredError, iter int, err error) {
+		if err != nil {
+			return
+		}
+
+		entry := HistoryEntry{
+			Iteration:    iter,
+			Loss:         t.Loss(mse),
+			Errors:       mse,
+			Alpha:        t.Alpha,
+			GradientNorm: t.GradientNorm(),
+			Seconds:      time.Since(history.start).Seconds(),
+		}
+
+		if len(history.validation) > 0 {
+			if errors, err := Evaluate(*history.net, history.validation); err == nil {
+				entry.ValidationErrors = errors.Average()
+				entry.ValidationLoss = t.Loss(entry.ValidationErrors)
+			}
+		}
+
+		history.Entries = append(history.Entries, entry)
+	})
+
+	return history
+}
+
+// WriteCSV writes one row per iteration with a header row.  The per output
+// errors are written as error_0, error_1 and so on, and the validation columns
+// are left empty when there is no validation data.
+func (h *History) WriteCSV(out io.Writer) error {
+	w := csv.NewWriter(out)
+
+	outputs := 0
+	if len(h.Entries) > 0 {
+		outputs = len(h.Entries[0].Errors)
+	}
+
+	header := []string{"iteration", "loss", "validation_loss", "alpha", "gradient_norm", "seconds"}
+	for i := 0; i < outputs; i++ {
+		header = append(header, "error_"+strconv.Itoa(i))
+	}
+	for i := 0; i < outputs; i++ {
+		header = append(header, "validation_error_"+strconv.Itoa(i))
+	}
+	w.Write(header)
+
+	format := func(v float64) string {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+
+	for _, entry := range h.Entries {
+		validationLoss := ""
+		if entry.ValidationErrors != nil {
+			validationLoss = format(entry.ValidationLoss)
+		}
+
+		record := []string{strconv.Itoa(entry.Iteration), format(entry.Loss), validationLoss,
+			format(entry.Alpha), format(entry.GradientNorm), format(entry.Seconds)}
+		for i := 0; i < outputs; i++ {
+			record = append(record, format(entry.Errors[i]))
+		}
+		for i := 0; i < outputs; i++ {
+			if entry.ValidationErrors != nil {
+				record = append(record, format(entry.ValidationErrors[i]))
+			} else {
+				record = append(record, "")
+			}
+		}
+		w.Write(record)
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// WriteJSON writes the entries as a JSON array.
+func (h *History) WriteJSON(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(h.Entries)
+}
@@ -0,0 +1,88 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTrainer_AddHistory(t *testing.T) {
+	net := MakeNetwork(2, 3, 1)
+	net.Randomize()
+
+	trainer := Trainer{}
+	history := trainer.AddHistory(&net, xorData())
+	trainer.AddSimpleStoppingCriteria(9, 0.0)
+
+	if err := trainer.Train(&net, xorData()); err != nil {
+		t.Errorf("Error during training: %v", err)
+	}
+
+	if len(history.Entries) != 10 {
+		t.Errorf("Expected 10 entries but got %d", len(history.Entries))
+	}
+
+	last := history.Entries[9]
+	if last.Iteration != 10 || outOfBoundsCheck(0.1, last.Alpha, 0.0001) || last.GradientNorm == 0.0 {
+		t.Errorf("Unexpected entry %+v", last)
+	}
+
+	if last.ValidationErrors == nil || last.ValidationLoss == 0.0 || last.Seconds < history.Entries[0].Seconds {
+		t.Errorf("Expected validation and timing to be recorded but got %+v", last)
+	}
+}
+
+func TestHistory_Export(t *testing.T) {
+	history := History{Entries: []HistoryEntry{
+		{Iteration: 1, Loss: 0.5, Errors: SquaredError{0.2, 0.3}, Alpha: 0.1, GradientNorm: 2.0, Seconds: 0.25},
+	}}
+
+	var buf bytes.Buffer
+	if err := history.WriteCSV(&buf); err != nil {
+		t.Errorf("Failed to write CSV: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || lines[1] != "1,0.5,,0.1,2,0.25,0.2,0.3,," {
+		t.Errorf("Unexpected CSV:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := history.WriteJSON(&buf); err != nil {
+		t.Errorf("Failed to write JSON: %v", err)
+	}
+
+	var entries []HistoryEntry
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil || len(entries) != 1 || entries[0].GradientNorm != 2.0 {
+		t.Errorf("Unexpected JSON %s: %v", buf.String(), err)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// groupByClass classifies the expected values of every example and groups the
+// examples by class label, using the same labels as a ClassificationReport.
+func (td TrainingData) groupByClass(classifier BasicClassifier) (map[string]TrainingData, error) {
+	result := map[string]TrainingData{}
+	for _, datum := range td {
+		classes, err := classifier(datum.Expected)
+		if err != nil {
+			return nil, err
+		}
+		label := confusionLabel(classes)
+		result[label] = append(result[label], datum)
+	}
+	return result, nil
+}
+
+// groupLabels returns the class labels in sorted order, so that resampling
+// does not depend on the order of map iteration.
+func groupLabels(groups map[string]TrainingData) []string {
+	labels := []string{}
+	for label := range groups {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// ClassWeights calculates a weight for each class that is inversely
+// proportional to how often the class appears in the expected values, so that
+// every class contributes equally to the error.  The weight for a class is the
+// number of examples divided by the number of classes times the number of
+// examples in the class.
+func (td TrainingData) ClassWeights(classifier BasicClassifier) (map[string]float64, error) {
+	groups, err := td.groupByClass(classifier)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]float64{}
+	for label, group := range groups {
+		result[label] = float64(len(td)) / float64(len(groups)*len(group))
+	}
+	return result, nil
+}
+
+// WeightByClass returns a copy of the training data with the Weight of every
+// example set from ClassWeights.
+func (td TrainingData) WeightByClass(classifier BasicClassifier) (TrainingData, error) {
+	weights, err := td.ClassWeights(classifier)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(TrainingData, len(td))
+	for idx, datum := range td {
+		classes, err := classifier(datum.Expected)
+		if err != nil {
+			return nil, err
+		}
+		result[idx] = datum
+		result[idx].Weight = weights[confusionLabel(classes)]
+	}
+	return result, nil
+}
+
+// Oversample returns new training data in which every class has as many
+// examples as the most common class.  Examples of the other classes are
+// repeated, chosen at random, to make up the difference.  The original examples
+// are all kept.
+func (td TrainingData) Oversample(classifier BasicClassifier) (TrainingData, error) {
+	groups, err := td.groupByClass(classifier)
+	if err != nil {
+		return nil, err
+	}
+
+	largest := 0
+	for _, group := range groups {
+		if len(group) > largest {
+			largest = len(group)
+		}
+	}
+
+	result := TrainingData{}
+	for _, label := range groupLabels(groups) {
+		group := groups[label]
+		result = append(result, group...)
+		for count := len(group); count < largest; count++ {
+			result = append(result, group[rand.Intn(len(group))])
+		}
+	}
+	return result, nil
+}
+
+// Undersample returns new training data in which every class has as many
+// examples as the least common class.  Examples of the other classes are chosen
+// at random and the rest are dropped.
+func (td TrainingData) Undersample(classifier BasicClassifier) (TrainingData, error) {
+	groups, err := td.groupByClass(classifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("Unable to undersample without data")
+	}
+
+	smallest := len(td)
+	for _, group := range groups {
+		if len(group) < smallest {
+			smallest = len(group)
+		}
+	}
+
+	result := TrainingData{}
+	for _, label := range groupLabels(groups) {
+		group := groups[label]
+		for _, idx := range rand.Perm(len(group))[:smallest] {
+			result = append(result, group[idx])
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,128 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import "testing"
+
+func imbalancedData() TrainingData {
+	td := TrainingData{}
+	for i := 0; i < 9; i++ {
+		td = append(td, TrainingDatum{Inputs: []float64{float64(i)}, Expected: []float64{0.9, 0.1}})
+	}
+	td = append(td, TrainingDatum{Inputs: []float64{9.0}, Expected: []float64{0.1, 0.9}})
+	return td
+}
+
+var imbalanceClassifier = MakeBestOfClassifier([]string{"legit", "fraud"})
+
+func TestTrainer_OneIterationWeighted(t *testing.T) {
+	weighted := TrainingData{
+		TrainingDatum{Inputs: []float64{1.0}, Expected: []float64{0.9}, Weight: 2.0},
+		TrainingDatum{Inputs: []float64{0.5}, Expected: []float64{0.1}},
+	}
+	repeated := TrainingData{weighted[0], weighted[0], weighted[1]}
+	repeated[0].Weight = 0.0
+	repeated[1].Weight = 0.0
+
+	trainer := Trainer{Alpha: 0.5, BatchUpdate: true}
+	first := MakeNetwork(1, 1)
+	second := MakeNetwork(1, 1)
+
+	firstErr, _ := trainer.OneIteration(&first, weighted)
+	secondErr, _ := trainer.OneIteration(&second, repeated)
+
+	if outOfBoundsCheck(secondErr[0], firstErr[0], 0.000001) {
+		t.Errorf("Expected a weight of 2 to match repeating the example, got %0.6f and %0.6f", firstErr[0], secondErr[0])
+	}
+
+	for col := range first.Layers[0].Weights[0] {
+		if outOfBoundsCheck(second.Layers[0].Weights[0][col], first.Layers[0].Weights[0][col], 0.000001) {
+			t.Errorf("Expected the same updates but got %v and %v", first.Layers[0].Weights, second.Layers[0].Weights)
+		}
+	}
+}
+
+func TestTrainer_OneIterationNegativeWeight(t *testing.T) {
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{1.0}, Expected: []float64{0.9}, Weight: 1.0},
+		TrainingDatum{Inputs: []float64{0.5}, Expected: []float64{0.1}, Weight: -1.0},
+	}
+
+	if err := td.Validate(); err == nil {
+		t.Error("Expected validation to reject a negative weight")
+	}
+
+	net := MakeNetwork(1, 1)
+	if _, err := (Trainer{Alpha: 0.5, BatchUpdate: true}).OneIteration(&net, td); err == nil {
+		t.Error("Expected training to reject a negative weight")
+	}
+
+	if net.Layers[0].Weights[0][0] != 0.0 {
+		t.Errorf("Expected the weights to be left alone but got %v", net.Layers[0].Weights)
+	}
+}
+
+func TestTrainingData_ClassWeights(t *testing.T) {
+	weights, err := imbalancedData().ClassWeights(imbalanceClassifier)
+	if err != nil {
+		t.Errorf("Failed to calculate class weights: %v", err)
+	}
+
+	if outOfBoundsCheck(10.0/18.0, weights["legit"], 0.0001) || outOfBoundsCheck(5.0, weights["fraud"], 0.0001) {
+		t.Errorf("Unexpected class weights %v", weights)
+	}
+
+	weighted, _ := imbalancedData().WeightByClass(imbalanceClassifier)
+	if outOfBoundsCheck(5.0, weighted[9].Weight, 0.0001) || outOfBoundsCheck(10.0/18.0, weighted[0].Weight, 0.0001) {
+		t.Errorf("Unexpected example weights %v", weighted)
+	}
+}
+
+func TestTrainingData_Oversample(t *testing.T) {
+	resampled, _ := imbalancedData().Oversample(imbalanceClassifier)
+	weights, _ := resampled.ClassWeights(imbalanceClassifier)
+
+	if len(resampled) != 18 || outOfBoundsCheck(1.0, weights["fraud"], 0.0001) {
+		t.Errorf("Expected 9 examples of each class but got %d examples with weights %v", len(resampled), weights)
+	}
+}
+
+func TestTrainingData_Undersample(t *testing.T) {
+	resampled, _ := imbalancedData().Undersample(imbalanceClassifier)
+
+	if len(resampled) != 2 {
+		t.Errorf("Expected 1 example of each class but got %d", len(resampled))
+	}
+
+	classes, _ := imbalanceClassifier(resampled[0].Expected)
+	other, _ := imbalanceClassifier(resampled[1].Expected)
+	if classes[0] == other[0] {
+		t.Errorf("Expected one example of each class but got %v", resampled)
+	}
+}
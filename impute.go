@@ -0,0 +1,259 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ImputeStrategy names the way an Imputer chooses the value used to replace
+// missing values.
+type ImputeStrategy string
+
+const (
+	// MeanImputation replaces missing values with the mean of the column.
+	MeanImputation ImputeStrategy = "mean"
+
+	// MedianImputation replaces missing values with the median of the column.
+	MedianImputation ImputeStrategy = "median"
+
+	// MostFrequentImputation replaces missing values with the most common value
+	// in the column, which suits columns of encoded categories.
+	MostFrequentImputation ImputeStrategy = "most_frequent"
+
+	// ConstantImputation replaces missing values with a fixed value.
+	ConstantImputation ImputeStrategy = "constant"
+)
+
+// InvalidValueError reports a NaN or infinite value in training data.  Row is
+// the position of the example in the data and Column is the position of the
+// value in either the inputs or, if Expected is true, the expected values.
+type InvalidValueError struct {
+	Row      int
+	Column   int
+	Expected bool
+	Value    float64
+}
+
+func (e *InvalidValueError) Error() string {
+	part := "input"
+	if e.Expected {
+		part = "expected value"
+	}
+	return fmt.Sprintf("Example %d has an invalid %s %v in column %d", e.Row, part, e.Value, e.Column)
+}
+
+// Imputer replaces missing values, which are NaN or infinite values such as
+// those loaded with MissingIsNaN, with values learned from training data.
+// Columns lists the columns that are imputed and Values holds the replacement
+// for each of them.  If Indicators is true, an extra value is appended for
+// each imputed column that is 1.0 if the value was missing and 0.0 otherwise,
+// so the network can learn from the fact that a value was missing.  The fields
+// are exported so the imputer can be saved along with the network.
+type Imputer struct {
+	Strategy   ImputeStrategy
+	Columns    []int
+	Values     []float64
+	Indicators bool
+}
+
+func isInvalid(value float64) bool {
+	return math.IsNaN(value) || math.IsInf(value, 0)
+}
+
+func checkValues(row int, values []float64, expected bool) error {
+	for col, value := range values {
+		if isInvalid(value) {
+			return &InvalidValueError{Row: row, Column: col, Expected: expected, Value: value}
+		}
+	}
+	return nil
+}
+
+// Validate checks the training data for NaN and infinite values, which would
+// otherwise be silently propagated through the network into the weights during
+// training.  It returns an InvalidValueError for the first one it finds.  It
+// also returns an error for an example with a negative or invalid Weight.
+func (td TrainingData) Validate() error {
+	for row, datum := range td {
+		if err := checkValues(row, datum.Inputs, false); err != nil {
+			return err
+		}
+
+		if err := checkValues(row, datum.Expected, true); err != nil {
+			return err
+		}
+
+		if err := checkWeight(row, datum.Weight); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FitMeanImputer creates an imputer that replaces missing values in each of the
+// given columns with the mean of the values present.  If no columns are given
+// every column is imputed.
+func FitMeanImputer(values [][]float64, indicators bool, columns ...int) (Imputer, error) {
+	return fitImputer(MeanImputation, values, indicators, columns, func(column []float64) float64 {
+		sum := 0.0
+		for _, v := range column {
+			sum += v
+		}
+		return sum / float64(len(column))
+	})
+}
+
+// FitMedianImputer creates an imputer that replaces missing values in each of
+// the given columns with the median of the values present.  If no columns are
+// given every column is imputed.
+func FitMedianImputer(values [][]float64, indicators bool, columns ...int) (Imputer, error) {
+	return fitImputer(MedianImputation, values, indicators, columns, func(column []float64) float64 {
+		return quantile(column, 0.5)
+	})
+}
+
+// FitMostFrequentImputer creates an imputer that replaces missing values in
+// each of the given columns with the most common value present, choosing the
+// smallest value when there is a tie.  If no columns are given every column is
+// imputed.
+func FitMostFrequentImputer(values [][]float64, indicators bool, columns ...int) (Imputer, error) {
+	return fitImputer(MostFrequentImputation, values, indicators, columns, func(column []float64) float64 {
+		best, bestCount, count := column[0], 0, 0
+		for idx := range column {
+			if idx > 0 && column[idx] != column[idx-1] {
+				count = 0
+			}
+			count++
+			if count > bestCount {
+				best, bestCount = column[idx], count
+			}
+		}
+		return best
+	})
+}
+
+// FitConstantImputer creates an imputer that replaces missing values in each of
+// the given columns with the constant.  The values are only used to count the
+// columns when no columns are given.
+func FitConstantImputer(values [][]float64, constant float64, indicators bool, columns ...int) (Imputer, error) {
+	return fitImputer(ConstantImputation, values, indicators, columns, func(column []float64) float64 {
+		return constant
+	})
+}
+
+// fitImputer collects the values present in each column in sorted order and
+// passes them to the fit function to learn the replacement value.
+func fitImputer(strategy ImputeStrategy, values [][]float64, indicators bool, columns []int, fit func([]float64) float64) (Imputer, error) {
+	if len(values) == 0 {
+		return Imputer{}, fmt.Errorf("Unable to fit an imputer without data")
+	}
+
+	if len(columns) == 0 {
+		for col := range values[0] {
+			columns = append(columns, col)
+		}
+	}
+
+	result := Imputer{Strategy: strategy, Columns: columns, Indicators: indicators}
+	for _, col := range columns {
+		column := []float64{}
+		for row := range values {
+			if col < 0 || col >= len(values[row]) {
+				return Imputer{}, fmt.Errorf("Unable to impute column %d", col)
+			}
+			if !isInvalid(values[row][col]) {
+				column = append(column, values[row][col])
+			}
+		}
+
+		if len(column) == 0 && strategy != ConstantImputation {
+			return Imputer{}, fmt.Errorf("Every value in column %d is missing", col)
+		}
+		sort.Float64s(column)
+		result.Values = append(result.Values, fit(column))
+	}
+	return result, nil
+}
+
+// Validate checks that the imputer has a value for each of its columns and
+// that every column is within vectors of the given width.  It is useful after
+// an imputer has been loaded from somewhere else.
+func (m Imputer) Validate(width int) error {
+	if len(m.Values) != len(m.Columns) {
+		return fmt.Errorf("Imputer has %d columns but %d values", len(m.Columns), len(m.Values))
+	}
+
+	for _, col := range m.Columns {
+		if col < 0 || col >= width {
+			return fmt.Errorf("Unable to impute column %d of %d values", col, width)
+		}
+	}
+	return nil
+}
+
+// Transform returns a copy of the values with the missing values replaced,
+// followed by the missing value indicators if the imputer has them.
+func (m Imputer) Transform(values []float64) ([]float64, error) {
+	if len(m.Values) != len(m.Columns) {
+		return nil, fmt.Errorf("Imputer has %d columns but %d values", len(m.Columns), len(m.Values))
+	}
+
+	result := make([]float64, len(values), len(values)+len(m.Columns))
+	copy(result, values)
+
+	indicators := []float64{}
+	for idx, col := range m.Columns {
+		if col < 0 || col >= len(values) {
+			return nil, fmt.Errorf("Unable to impute column %d of %d values", col, len(values))
+		}
+
+		missing := 0.0
+		if isInvalid(values[col]) {
+			result[col] = m.Values[idx]
+			missing = 1.0
+		}
+		indicators = append(indicators, missing)
+	}
+
+	if m.Indicators {
+		result = append(result, indicators...)
+	}
+	return result, nil
+}
+
+// Size returns the number of values the imputer adds to each vector.
+func (m Imputer) Size() int {
+	if m.Indicators {
+		return len(m.Columns)
+	}
+	return 0
+}
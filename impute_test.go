@@ -0,0 +1,174 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+var nan = math.NaN()
+
+var missingData = [][]float64{
+	{1.0, 5.0, 2.0},
+	{nan, 5.0, 2.0},
+	{3.0, nan, 4.0},
+	{8.0, 7.0, nan},
+}
+
+func TestTrainingData_Validate(t *testing.T) {
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{1.0, 2.0}, Expected: []float64{0.1}},
+		TrainingDatum{Inputs: []float64{1.0, 2.0}, Expected: []float64{math.Inf(1)}},
+	}
+
+	var invalid *InvalidValueError
+	if err := td.Validate(); !errors.As(err, &invalid) {
+		t.Fatalf("Expected an InvalidValueError but got %v", err)
+	}
+
+	if invalid.Row != 1 || invalid.Column != 0 || !invalid.Expected {
+		t.Errorf("Expected row 1, expected column 0 but got %v", invalid)
+	}
+
+	if err := xorData().Validate(); err != nil {
+		t.Errorf("Expected valid data but got %v", err)
+	}
+}
+
+func TestTrainer_OneIterationInvalid(t *testing.T) {
+	td := TrainingData{TrainingDatum{Inputs: []float64{nan, 1.0}, Expected: []float64{0.1}}}
+	net := MakeNetwork(2, 1)
+
+	trainer := Trainer{}
+	if _, err := trainer.OneIteration(&net, td); err == nil {
+		t.Error("Expected an error for a NaN input")
+	}
+
+	if net.Layers[0].Weights[0][0] != 0.0 {
+		t.Errorf("The weights should not have been updated")
+	}
+}
+
+func TestFitImputers(t *testing.T) {
+	mean, _ := FitMeanImputer(missingData, false)
+	median, _ := FitMedianImputer(missingData, false)
+	frequent, _ := FitMostFrequentImputer(missingData, false)
+	constant, _ := FitConstantImputer(missingData, -1.0, false)
+
+	cases := []struct {
+		imputer  Imputer
+		expected []float64
+	}{
+		{mean, []float64{4.0, 17.0 / 3.0, 8.0 / 3.0}},
+		{median, []float64{3.0, 5.0, 2.0}},
+		{frequent, []float64{1.0, 5.0, 2.0}},
+		{constant, []float64{-1.0, -1.0, -1.0}},
+	}
+
+	for _, c := range cases {
+		for col := range c.expected {
+			values := []float64{0.0, 0.0, 0.0}
+			values[col] = nan
+
+			imputed, _ := c.imputer.Transform(values)
+			if outOfBoundsCheck(c.expected[col], imputed[col], 0.0001) {
+				t.Errorf("%s imputer expected %0.4f in column %d but got %0.4f", c.imputer.Strategy, c.expected[col], col, imputed[col])
+			}
+		}
+	}
+}
+
+func TestImputer_Indicators(t *testing.T) {
+	imputer, err := FitMeanImputer(missingData, true, 0, 1)
+	if err != nil {
+		t.Errorf("Failed to fit imputer: %v", err)
+	}
+
+	imputed, _ := imputer.Transform([]float64{nan, 6.0, 1.0})
+	if len(imputed) != 5 || imputed[3] != 1.0 || imputed[4] != 0.0 || outOfBoundsCheck(4.0, imputed[0], 0.0001) {
+		t.Errorf("Expected [4 6 1 1 0] but got %v", imputed)
+	}
+
+	if _, err := FitMeanImputer([][]float64{{nan}, {nan}}, false); err == nil {
+		t.Error("Expected an error when every value is missing")
+	}
+}
+
+func TestPipeline_Imputers(t *testing.T) {
+	imputer, _ := FitMeanImputer([][]float64{{2.0}, {4.0}}, true)
+	p := Pipeline{Fields: []Field{{Name: "x"}}, Imputers: []Imputer{imputer}, Network: MakeNetwork(2, 1)}
+
+	if err := p.Validate(); err != nil {
+		t.Errorf("Expected a valid pipeline but got %v", err)
+	}
+
+	inputs, err := p.Inputs([]string{"NA"})
+	if err != nil || outOfBoundsCheck(3.0, inputs[0], 0.0001) || inputs[1] != 1.0 {
+		t.Errorf("Expected [3 1] but got %v, %v", inputs, err)
+	}
+
+	p.Imputers = nil
+	p.Network = MakeNetwork(1, 1)
+	if _, err := p.Inputs([]string{"NA"}); err == nil {
+		t.Error("Expected an error for a missing value without an imputer")
+	}
+}
+
+func TestImputer_Invalid(t *testing.T) {
+	short := Imputer{Strategy: MeanImputation, Columns: []int{0, 1}, Values: []float64{1.0}}
+	if err := short.Validate(2); err == nil {
+		t.Error("Expected an error for a missing value")
+	}
+	if _, err := short.Transform([]float64{nan, nan}); err == nil {
+		t.Error("Expected an error transforming with a missing value")
+	}
+
+	negative := Imputer{Strategy: MeanImputation, Columns: []int{-1}, Values: []float64{1.0}}
+	if err := negative.Validate(2); err == nil {
+		t.Error("Expected an error for a negative column")
+	}
+	if _, err := negative.Transform([]float64{nan, nan}); err == nil {
+		t.Error("Expected an error transforming a negative column")
+	}
+
+	p := Pipeline{Fields: []Field{{Name: "x"}}, Imputers: []Imputer{short}, Network: MakeNetwork(1, 1)}
+	if err := p.Validate(); err == nil {
+		t.Error("Expected an error for a pipeline with an invalid imputer")
+	}
+
+	// An imputer may use the indicators added by the imputer before it.
+	first := Imputer{Strategy: MeanImputation, Columns: []int{0}, Values: []float64{1.0}, Indicators: true}
+	second := Imputer{Strategy: ConstantImputation, Columns: []int{1}, Values: []float64{0.0}}
+	p = Pipeline{Fields: []Field{{Name: "x"}}, Imputers: []Imputer{first, second}, Network: MakeNetwork(2, 1)}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Expected a valid pipeline but got %v", err)
+	}
+}
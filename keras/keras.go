@@ -0,0 +1,294 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package keras imports fully connected models trained with Keras.  Instead of
+// HDF5 files it reads the JSON architecture written by model.to_json() and a
+// JSON dump of the weights, so it needs no dependencies outside the standard
+// library.
+package keras
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+// model is the JSON written by model.to_json().  Only Sequential models are
+// supported.  Their config is either an object with a list of layers or, in
+// older versions of Keras, the list of layers itself.
+type model struct {
+	ClassName string          `json:"class_name"`
+	Config    json.RawMessage `json:"config"`
+}
+
+type sequentialConfig struct {
+	Layers []layer `json:"layers"`
+}
+
+type layer struct {
+	ClassName string      `json:"class_name"`
+	Config    layerConfig `json:"config"`
+}
+
+// layerConfig holds the layer settings that matter to a fully connected
+// network.  Keras 3 names the input shape batch_shape and older versions name
+// it batch_input_shape.
+type layerConfig struct {
+	Name            string `json:"name"`
+	Units           int    `json:"units"`
+	Activation      string `json:"activation"`
+	UseBias         *bool  `json:"use_bias"`
+	BatchShape      []*int `json:"batch_shape"`
+	BatchInputShape []*int `json:"batch_input_shape"`
+}
+
+// denseLayer is a Dense layer waiting for its activation.
+type denseLayer struct {
+	name    string
+	kernel  [][]float64
+	bias    []float64
+	pending bool
+}
+
+// Import reads a Keras model architecture and its weights and converts them
+// into a Network.  The weights are either a list of arrays in the order that
+// model.get_weights() returns them, as written by
+//
+//	json.dump([w.tolist() for w in model.get_weights()], f)
+//
+// or an object that maps each layer's name to its list of arrays, the kernel
+// followed by the bias.  The model must be a chain of Dense layers using the
+// sigmoid activation, which is the only activation a Network supports, either
+// directly or through a following Activation layer.  InputLayer, Dropout and
+// Flatten layers do nothing at inference time and are skipped; any other
+// layer is an error.
+func Import(architecture, weights io.Reader) (gofeedforward.Network, error) {
+	layers, err := readArchitecture(architecture)
+	if err != nil {
+		return gofeedforward.Network{}, err
+	}
+
+	next, err := readWeights(weights, layers)
+	if err != nil {
+		return gofeedforward.Network{}, err
+	}
+
+	net := gofeedforward.Network{}
+	inputSize := 0
+	var dense *denseLayer
+	for idx, l := range layers {
+		name := l.Config.Name
+		if name == "" {
+			name = fmt.Sprintf("layer %d", idx)
+		}
+
+		switch l.ClassName {
+		case "InputLayer":
+			if inputSize, err = l.Config.inputSize(); err != nil {
+				return net, fmt.Errorf("Layer %q: %v", name, err)
+			}
+
+		case "Dropout", "Flatten":
+
+		case "Dense":
+			if dense != nil {
+				return net, fmt.Errorf("Layer %q: Dense follows layer %q, which has a linear activation", name, dense.name)
+			}
+
+			if size, err := l.Config.inputSize(); err == nil {
+				inputSize = size
+			}
+
+			dense = &denseLayer{name: name}
+			if dense.kernel, dense.bias, err = next(l); err != nil {
+				return net, fmt.Errorf("Layer %q: %v", name, err)
+			}
+
+			if len(net.Layers) == 0 && inputSize > 0 && len(dense.kernel) != inputSize {
+				return net, fmt.Errorf("Layer %q: kernel has %d rows for %d inputs", name, len(dense.kernel), inputSize)
+			}
+
+			switch l.Config.Activation {
+			case "sigmoid":
+				net.Layers = append(net.Layers, dense.layer())
+				dense = nil
+			case "linear", "":
+			default:
+				return net, fmt.Errorf("Layer %q: activation %q is not supported, a Network only uses sigmoid", name, l.Config.Activation)
+			}
+
+		case "Activation":
+			if dense == nil {
+				return net, fmt.Errorf("Layer %q: Activation must follow a Dense layer with a linear activation", name)
+			}
+
+			if l.Config.Activation != "sigmoid" {
+				return net, fmt.Errorf("Layer %q: activation %q is not supported, a Network only uses sigmoid", name, l.Config.Activation)
+			}
+			net.Layers = append(net.Layers, dense.layer())
+			dense = nil
+
+		default:
+			return net, fmt.Errorf("Layer %q: %s layers are not supported", name, l.ClassName)
+		}
+	}
+
+	if dense != nil {
+		return net, fmt.Errorf("Layer %q has a linear activation, but a Network applies sigmoid to every layer", dense.name)
+	}
+
+	if err := net.Validate(); err != nil {
+		return net, err
+	}
+	return net, nil
+}
+
+func readArchitecture(r io.Reader) ([]layer, error) {
+	var m model
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("Unable to read Keras architecture: %v", err)
+	}
+
+	if m.ClassName != "Sequential" {
+		return nil, fmt.Errorf("Only Sequential Keras models are supported, not %q", m.ClassName)
+	}
+
+	var config sequentialConfig
+	if bytes.HasPrefix(bytes.TrimSpace(m.Config), []byte("[")) {
+		if err := json.Unmarshal(m.Config, &config.Layers); err != nil {
+			return nil, fmt.Errorf("Unable to read Keras layers: %v", err)
+		}
+	} else if err := json.Unmarshal(m.Config, &config); err != nil {
+		return nil, fmt.Errorf("Unable to read Keras layers: %v", err)
+	}
+	return config.Layers, nil
+}
+
+// readWeights reads the weights dump and returns a function that returns the
+// kernel and bias of each Dense layer in turn.
+func readWeights(r io.Reader, layers []layer) (func(layer) ([][]float64, []float64, error), error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("Unable to read Keras weights: %v", err)
+	}
+
+	var list []json.RawMessage
+	byName := map[string][]json.RawMessage{}
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, fmt.Errorf("Unable to read Keras weights: %v", err)
+		}
+	} else if err := json.Unmarshal(raw, &byName); err != nil {
+		return nil, fmt.Errorf("Unable to read Keras weights: %v", err)
+	}
+
+	dense, expected := 0, 0
+	for _, l := range layers {
+		if l.ClassName == "Dense" {
+			dense++
+			expected++
+			if l.Config.useBias() {
+				expected++
+			}
+		}
+	}
+	if list != nil && len(list) != expected {
+		return nil, fmt.Errorf("Keras weights have %d arrays but the %d Dense layers need %d", len(list), dense, expected)
+	}
+
+	return func(l layer) ([][]float64, []float64, error) {
+		arrays := byName[l.Config.Name]
+		if list != nil {
+			count := 1
+			if l.Config.useBias() {
+				count = 2
+			}
+			arrays, list = list[:count], list[count:]
+		} else if arrays == nil {
+			return nil, nil, fmt.Errorf("no weights for the layer")
+		}
+
+		var kernel [][]float64
+		if len(arrays) < 1 || json.Unmarshal(arrays[0], &kernel) != nil || len(kernel) == 0 {
+			return nil, nil, fmt.Errorf("kernel is not a two dimensional array")
+		}
+
+		for _, row := range kernel {
+			if len(row) != l.Config.Units {
+				return nil, nil, fmt.Errorf("kernel has %d columns for %d units", len(row), l.Config.Units)
+			}
+		}
+
+		bias := make([]float64, l.Config.Units)
+		if l.Config.useBias() {
+			if len(arrays) != 2 || json.Unmarshal(arrays[1], &bias) != nil {
+				return nil, nil, fmt.Errorf("bias is not a one dimensional array")
+			}
+
+			if len(bias) != l.Config.Units {
+				return nil, nil, fmt.Errorf("bias has %d values for %d units", len(bias), l.Config.Units)
+			}
+		}
+		return kernel, bias, nil
+	}, nil
+}
+
+func (c layerConfig) useBias() bool {
+	return c.UseBias == nil || *c.UseBias
+}
+
+// inputSize returns the size of the input from the layer's input shape, which
+// must be a batch of vectors.
+func (c layerConfig) inputSize() (int, error) {
+	shape := c.BatchShape
+	if shape == nil {
+		shape = c.BatchInputShape
+	}
+
+	if len(shape) != 2 || shape[1] == nil {
+		return 0, fmt.Errorf("input shape must be a batch of vectors")
+	}
+	return *shape[1], nil
+}
+
+// layer returns the Dense layer as a Layer.  The kernel has one row per input,
+// so it is transposed into a Core, and the bias becomes the Core's last column.
+func (d *denseLayer) layer() gofeedforward.Layer {
+	inputs, outputs := len(d.kernel), len(d.bias)
+	result := gofeedforward.MakeLayer(inputs, outputs)
+	for o := 0; o < outputs; o++ {
+		for i := 0; i < inputs; i++ {
+			result.Weights[o][i] = d.kernel[i][o]
+		}
+		result.Weights[o][inputs] = d.bias[o]
+	}
+	return result
+}
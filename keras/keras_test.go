@@ -0,0 +1,143 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package keras
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+// A 2, 3, 1 model as written by model.to_json() in Keras 3, trimmed of the
+// settings that do not matter to the import.
+const sequential = `{
+  "module": "keras",
+  "class_name": "Sequential",
+  "config": {
+    "name": "sequential",
+    "layers": [
+      {"class_name": "InputLayer", "config": {"batch_shape": [null, 2], "dtype": "float32", "name": "input_layer"}},
+      {"class_name": "Dense", "config": {"name": "hidden", "units": 3, "activation": "sigmoid", "use_bias": true}},
+      {"class_name": "Dropout", "config": {"name": "dropout", "rate": 0.2}},
+      {"class_name": "Dense", "config": {"name": "output", "units": 1, "activation": "linear", "use_bias": true}},
+      {"class_name": "Activation", "config": {"name": "squash", "activation": "sigmoid"}}
+    ]
+  },
+  "keras_version": "3.4.1",
+  "backend": "tensorflow"
+}`
+
+const weightList = `[
+  [[0.1, 0.2, 0.3], [0.4, 0.5, 0.6]],
+  [0.01, 0.02, 0.03],
+  [[1.0], [-1.0], [0.5]],
+  [0.25]
+]`
+
+const weightMap = `{
+  "hidden": [[[0.1, 0.2, 0.3], [0.4, 0.5, 0.6]], [0.01, 0.02, 0.03]],
+  "output": [[[1.0], [-1.0], [0.5]], [0.25]]
+}`
+
+// expectedOutput calculates the model's output by hand.
+func expectedOutput(inputs []float64) float64 {
+	kernel := [][]float64{{0.1, 0.2, 0.3}, {0.4, 0.5, 0.6}}
+	bias := []float64{0.01, 0.02, 0.03}
+	output := 0.25
+	for o, weight := range []float64{1.0, -1.0, 0.5} {
+		hidden := bias[o] + inputs[0]*kernel[0][o] + inputs[1]*kernel[1][o]
+		output += weight * gofeedforward.Sigmoid(hidden)
+	}
+	return gofeedforward.Sigmoid(output)
+}
+
+func TestImport(t *testing.T) {
+	for name, weights := range map[string]string{"list": weightList, "map": weightMap} {
+		net, err := Import(strings.NewReader(sequential), strings.NewReader(weights))
+		if err != nil {
+			t.Fatalf("%s: failed to import: %v", name, err)
+		}
+
+		if net.InputSize() != 2 || net.OutputSize() != 1 || len(net.Layers) != 2 {
+			t.Fatalf("%s: expected a 2, 3, 1 network but got %d layers", name, len(net.Layers))
+		}
+
+		for _, inputs := range [][]float64{{0.0, 0.0}, {1.0, -2.0}, {0.5, 0.5}} {
+			outputs, err := net.Process(inputs)
+			if err != nil {
+				t.Fatalf("%s: failed to process: %v", name, err)
+			}
+
+			if expected := expectedOutput(inputs); math.Abs(outputs[0]-expected) > 1e-12 {
+				t.Errorf("%s: expected %v for %v but got %v", name, expected, inputs, outputs[0])
+			}
+		}
+	}
+}
+
+func TestImport_OlderKeras(t *testing.T) {
+	architecture := `{"class_name": "Sequential", "config": [
+		{"class_name": "Dense", "config": {"name": "d", "units": 1, "activation": "sigmoid", "use_bias": false, "batch_input_shape": [null, 2]}}
+	]}`
+
+	net, err := Import(strings.NewReader(architecture), strings.NewReader(`[[[2.0], [3.0]]]`))
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	weights := net.Layers[0].Weights[0]
+	if len(weights) != 3 || weights[0] != 2.0 || weights[1] != 3.0 || weights[2] != 0.0 {
+		t.Errorf("Expected weights [2 3 0] but got %v", weights)
+	}
+}
+
+func TestImport_Errors(t *testing.T) {
+	cases := map[string]struct {
+		architecture, weights, message string
+	}{
+		"relu":          {strings.Replace(sequential, `"activation": "sigmoid", "use_bias"`, `"activation": "relu", "use_bias"`, 1), weightList, `activation "relu" is not supported`},
+		"linear output": {strings.Replace(sequential, `{"class_name": "Activation"`, `{"class_name": "Dropout"`, 1), weightList, "linear activation"},
+		"conv":          {strings.Replace(sequential, `"Dropout"`, `"Conv2D"`, 1), weightList, "Conv2D layers are not supported"},
+		"functional":    {strings.Replace(sequential, `"Sequential"`, `"Functional"`, 1), weightList, "Only Sequential"},
+		"missing array": {sequential, `[[[0.1, 0.2, 0.3], [0.4, 0.5, 0.6]], [0.01, 0.02, 0.03]]`, "need 4"},
+		"missing layer": {sequential, `{"hidden": [[[0.1, 0.2, 0.3], [0.4, 0.5, 0.6]], [0.01, 0.02, 0.03]]}`, "no weights"},
+		"kernel shape":  {sequential, strings.Replace(weightList, "[0.4, 0.5, 0.6]", "[0.4, 0.5]", 1), "columns"},
+		"input shape":   {strings.Replace(sequential, "[null, 2]", "[null, 4]", 1), weightList, "4 inputs"},
+		"bad json":      {sequential, `[[`, "Unable to read"},
+	}
+
+	for name, c := range cases {
+		_, err := Import(strings.NewReader(c.architecture), strings.NewReader(c.weights))
+		if err == nil || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("%s: expected an error containing %q but got %v", name, c.message, err)
+		}
+	}
+}
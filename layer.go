@@ -41,10 +41,11 @@ type Core [][]float64
 
 // Layer is a layer in a network and is composed of the weights, the last set of
 // inputs presented tot he weights and the last output produced by the weights.
+// Only the weights are saved when a layer is encoded as JSON.
 type Layer struct {
 	Weights Core
-	Inputs  []float64
-	Outputs []float64
+	Inputs  []float64 `json:"-"`
+	Outputs []float64 `json:"-"`
 }
 
 // MakeCore creates a new two dimensional array of values.  if inputs are set to
@@ -112,6 +113,18 @@ func (c Core) Add(other Core) (Core, error) {
 	return result, nil
 }
 
+// Scale multiplies every value in the Core by the given factor and returns the
+// result as a separate Core.
+func (c Core) Scale(factor float64) Core {
+	result := MakeCore(c.InputSize(), c.OutputSize())
+	for row := range c {
+		for col := range c[row] {
+			result[row][col] = c[row][col] * factor
+		}
+	}
+	return result
+}
+
 // MakeLayer creates a new layer.  A layer is has an implicit bias input value
 // of 1.0, so a layer with 5 inputs and 3 outputs actually needs a weight
 // matrix of 3 x 6.
@@ -127,7 +140,9 @@ func MakeLayer(inputs, outputs int) Layer {
 func (l *Layer) Process(inputs []float64) ([]float64, error) {
 	l.Inputs = inputs
 
-	biasedInputs := append(inputs, 1.0)
+	// Limiting the capacity makes append copy, so the caller's slice is never
+	// written to even when the same examples are shared between goroutines.
+	biasedInputs := append(inputs[:len(inputs):len(inputs)], 1.0)
 	outputs, err := l.Weights.Process(biasedInputs)
 
 	if err != nil {
@@ -157,3 +172,38 @@ func (l *Layer) UpdateWeights(updates Core) error {
 	l.Weights, err = l.Weights.Add(updates)
 	return err
 }
+
+// Backward propagates a gradient back through the layer.  The argument is the
+// gradient of some error with respect to the layer's outputs.  It returns the
+// gradient with respect to each of the weights (a Core the same size as the
+// layer's weights) and the gradient with respect to each of the inputs.  The
+// gradient is calculated at the inputs and outputs from the last call to
+// Process, so the layer must have processed an input first.
+func (l Layer) Backward(outputGrad []float64) (Core, []float64, error) {
+	if len(outputGrad) != len(l.Outputs) {
+		return nil, nil, fmt.Errorf("Expected %d output gradients but got %d", len(l.Outputs), len(outputGrad))
+	}
+
+	deltas := make([]float64, len(l.Outputs))
+	for idx, out := range l.Outputs {
+		deltas[idx] = outputGrad[idx] * out * (1 - out)
+	}
+
+	// An update with a learning rate of -1.0 is the gradient itself.
+	return calculateUpdate(l, deltas, -1.0), inputGradient(l, deltas), nil
+}
+
+// inputGradient calculates the gradient with respect to the layer's inputs
+// from the deltas at the layer's weighted sums.  The bias input is not
+// included in the result.
+func inputGradient(l Layer, deltas []float64) []float64 {
+	result := make([]float64, len(l.Inputs))
+	for col := range l.Inputs {
+		sum := 0.0
+		for row := range l.Weights {
+			sum += deltas[row] * l.Weights[row][col]
+		}
+		result[col] = sum
+	}
+	return result
+}
@@ -176,7 +176,7 @@ func TestLayer_Process(t *testing.T) {
 
 	outputs, _ := l.Process([]float64{1.0, 2.0})
 	if outOfBoundsCheck(0.5, outputs[0], 0.001) {
-		t.Errorf("Expected 0.5 but got %d", outputs[0])
+		t.Errorf("Expected 0.5 but got %0.4f", outputs[0])
 	}
 }
 
@@ -185,7 +185,17 @@ func TestLayer_ProcessKeepOutputs(t *testing.T) {
 
 	l.Process([]float64{1.0, 2.0})
 	if outOfBoundsCheck(0.5, l.Outputs[0], 0.001) {
-		t.Errorf("Expected 0.5 but got %d", l.Outputs[0])
+		t.Errorf("Expected 0.5 but got %0.4f", l.Outputs[0])
+	}
+}
+
+func TestLayer_ProcessLeavesInputs(t *testing.T) {
+	l := MakeLayer(2, 1)
+
+	backing := []float64{1.0, 2.0, 7.0}
+	l.Process(backing[:2])
+	if backing[2] != 7.0 {
+		t.Errorf("Expected the inputs' spare capacity to be untouched but got %0.4f", backing[2])
 	}
 }
 
@@ -212,3 +222,50 @@ func TestLayer_UpdateWeights(t *testing.T) {
 		}
 	}
 }
+
+func TestCore_Scale(t *testing.T) {
+	c := MakeCore(2, 1)
+	c[0][0] = 1.0
+	c[0][1] = -2.0
+
+	scaled := c.Scale(0.5)
+	if outOfBoundsCheck(0.5, scaled[0][0], 0.001) || outOfBoundsCheck(-1.0, scaled[0][1], 0.001) {
+		t.Errorf("Expected [0.5 -1.0] but got %v", scaled[0])
+	}
+
+	if outOfBoundsCheck(1.0, c[0][0], 0.001) {
+		t.Errorf("Scale should not modify the original core")
+	}
+}
+
+func TestLayer_Backward(t *testing.T) {
+	l := MakeLayer(2, 1)
+	l.Weights[0][0] = 1.0
+	l.Weights[0][1] = 2.0
+
+	l.Process([]float64{1.0, 2.0})
+	weights, inputs, err := l.Backward([]float64{1.0})
+	if err != nil {
+		t.Errorf("Failed to backpropagate: %v", err)
+	}
+
+	out := l.Outputs[0]
+	delta := out * (1 - out)
+	if outOfBoundsCheck(delta*1.0, weights[0][0], 0.0001) || outOfBoundsCheck(delta*2.0, weights[0][1], 0.0001) ||
+		outOfBoundsCheck(delta, weights[0][2], 0.0001) {
+		t.Errorf("Unexpected weight gradient %v", weights)
+	}
+
+	if len(inputs) != 2 || outOfBoundsCheck(delta*1.0, inputs[0], 0.0001) || outOfBoundsCheck(delta*2.0, inputs[1], 0.0001) {
+		t.Errorf("Unexpected input gradient %v", inputs)
+	}
+}
+
+func TestLayer_BackwardSizeError(t *testing.T) {
+	l := MakeLayer(2, 1)
+	l.Process([]float64{1.0, 2.0})
+
+	if _, _, err := l.Backward([]float64{1.0, 1.0}); err == nil {
+		t.Error("Expected an error but got no error")
+	}
+}
@@ -0,0 +1,217 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the version of the library.  It is recorded in the metadata of
+// saved networks, and a network saved by a newer major version is refused when
+// it is loaded.
+const Version = "1.0.0"
+
+// TrainerSettings are the hyperparameters of the Trainer that trained a
+// network.
+type TrainerSettings struct {
+	Alpha         float64
+	BatchUpdate   bool
+	ShuffleRounds int
+	OutputWeights []float64 `json:",omitempty"`
+}
+
+// Metadata records where a network came from and how it is meant to be used.
+// Inputs and Outputs name each network input and output, Classes names the
+// classes for MakeBestOfClassifier, DatasetHash identifies the training data,
+// either with HashTrainingData or a hash of the file it was read from, and
+// Metrics holds named results such as the final loss.  Every field is
+// optional, but the names that are present must match the size of the network.
+type Metadata struct {
+	Inputs      []string           `json:",omitempty"`
+	Outputs     []string           `json:",omitempty"`
+	Classes     []string           `json:",omitempty"`
+	DatasetHash string             `json:",omitempty"`
+	Trainer     *TrainerSettings   `json:",omitempty"`
+	Metrics     map[string]float64 `json:",omitempty"`
+	Created     time.Time
+	Version     string
+}
+
+// MakeMetadata creates metadata stamped with the current time and the
+// library version.
+func MakeMetadata() Metadata {
+	return Metadata{Created: time.Now().UTC().Truncate(time.Second), Version: Version}
+}
+
+// Settings returns the hyperparameters of the trainer.
+func (t Trainer) Settings() TrainerSettings {
+	return TrainerSettings{
+		Alpha:         t.Alpha,
+		BatchUpdate:   t.BatchUpdate,
+		ShuffleRounds: t.ShuffleRounds,
+		OutputWeights: t.OutputWeights,
+	}
+}
+
+// RecordTraining records the trainer's hyperparameters and the hash of the
+// data it was trained on.  The hash depends on the order of the examples, so
+// record the data as it was loaded, before it is split or shuffled by Shuffle
+// or a Trainer with ShuffleRounds.
+func (m *Metadata) RecordTraining(trainer Trainer, data TrainingData) {
+	settings := trainer.Settings()
+	m.Trainer = &settings
+	m.DatasetHash = HashTrainingData(data)
+}
+
+// HashTrainingData returns a SHA-256 hash of the inputs, expected values and
+// weights of every example, in order.  Shuffling the data changes the hash.
+func HashTrainingData(data TrainingData) string {
+	hash := sha256.New()
+	var buf [8]byte
+	write := func(values []float64) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(len(values)))
+		hash.Write(buf[:])
+		for _, value := range values {
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(value))
+			hash.Write(buf[:])
+		}
+	}
+
+	for _, datum := range data {
+		write(datum.Inputs)
+		write(datum.Expected)
+		write([]float64{datum.weight()})
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// Validate checks that the names match the network's inputs and outputs and
+// that the network was not saved by a newer major version of the library.
+func (m Metadata) Validate(net Network) error {
+	if len(m.Inputs) > 0 && len(m.Inputs) != net.InputSize() {
+		return fmt.Errorf("Metadata names %d inputs but the network has %d", len(m.Inputs), net.InputSize())
+	}
+
+	if len(m.Outputs) > 0 && len(m.Outputs) != net.OutputSize() {
+		return fmt.Errorf("Metadata names %d outputs but the network has %d", len(m.Outputs), net.OutputSize())
+	}
+
+	if len(m.Classes) > 0 && len(m.Classes) != net.OutputSize() {
+		return fmt.Errorf("Metadata names %d classes but the network has %d outputs", len(m.Classes), net.OutputSize())
+	}
+
+	if m.Version != "" {
+		saved, err := majorVersion(m.Version)
+		if err != nil {
+			return err
+		}
+
+		current, _ := majorVersion(Version)
+		if saved > current {
+			return fmt.Errorf("Network was saved by version %s, which is newer than version %s", m.Version, Version)
+		}
+	}
+	return nil
+}
+
+func majorVersion(version string) (int, error) {
+	major, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), ".")
+	result, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid version %q", version)
+	}
+	return result, nil
+}
+
+// CheckInputs checks that the names of the inputs provided, such as the
+// columns of a data file, are the inputs the network was trained with and are
+// in the same order.  It accepts any names if the metadata does not name the
+// inputs.
+func (m Metadata) CheckInputs(names []string) error {
+	if len(m.Inputs) == 0 {
+		return nil
+	}
+
+	if len(names) != len(m.Inputs) {
+		return fmt.Errorf("Expected the %d inputs %s but got %d inputs", len(m.Inputs), strings.Join(m.Inputs, ", "), len(names))
+	}
+
+	for idx, name := range names {
+		if name != m.Inputs[idx] {
+			return fmt.Errorf("Expected input %d to be %s but got %s", idx, m.Inputs[idx], name)
+		}
+	}
+	return nil
+}
+
+// Save writes the network, with its metadata, as JSON.
+func (n Network) Save(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(n)
+}
+
+// LoadNetwork reads a network written by Save and validates it, including its
+// metadata.  If input names are given, they are checked against the inputs
+// named in the metadata with CheckInputs.
+func LoadNetwork(r io.Reader, inputs ...string) (Network, error) {
+	var result Network
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return Network{}, err
+	}
+
+	if err := result.Validate(); err != nil {
+		return Network{}, err
+	}
+
+	if len(inputs) > 0 && result.Metadata != nil {
+		if err := result.Metadata.CheckInputs(inputs); err != nil {
+			return Network{}, err
+		}
+	}
+	return result, nil
+}
+
+// BestOfClassifier returns a MakeBestOfClassifier for the classes named in the
+// network's metadata.
+func (n Network) BestOfClassifier() (BasicClassifier, error) {
+	if n.Metadata == nil || len(n.Metadata.Classes) == 0 {
+		return nil, fmt.Errorf("Network metadata does not name any classes")
+	}
+	return MakeBestOfClassifier(n.Metadata.Classes), nil
+}
@@ -0,0 +1,153 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNetwork_SaveLoadMetadata(t *testing.T) {
+	net := MakeNetwork(2, 3, 2)
+	net.Randomize()
+
+	data := TrainingData{{Inputs: []float64{0.0, 1.0}, Expected: []float64{0.9, 0.1}}}
+	trainer := Trainer{Alpha: 0.5, BatchUpdate: true}
+
+	metadata := MakeMetadata()
+	metadata.Inputs = []string{"height", "width"}
+	metadata.Outputs = []string{"small", "large"}
+	metadata.Classes = []string{"small", "large"}
+	metadata.Metrics = map[string]float64{"loss": 0.01}
+	metadata.RecordTraining(trainer, data)
+	net.Metadata = &metadata
+
+	var buf bytes.Buffer
+	if err := net.Save(&buf); err != nil {
+		t.Fatalf("Failed to save network: %v", err)
+	}
+	saved := buf.String()
+
+	loaded, err := LoadNetwork(strings.NewReader(saved), "height", "width")
+	if err != nil {
+		t.Fatalf("Failed to load network: %v", err)
+	}
+
+	m := loaded.Metadata
+	if m == nil || m.Version != Version || !m.Created.Equal(metadata.Created) || m.DatasetHash != HashTrainingData(data) ||
+		m.Trainer == nil || m.Trainer.Alpha != 0.5 || !m.Trainer.BatchUpdate || m.Metrics["loss"] != 0.01 {
+		t.Errorf("Unexpected metadata %+v", m)
+	}
+
+	if _, err := LoadNetwork(strings.NewReader(saved), "width", "height"); err == nil {
+		t.Error("Expected an error for inputs in the wrong order")
+	}
+
+	if _, err := LoadNetwork(strings.NewReader(saved), "height"); err == nil {
+		t.Error("Expected an error for too few inputs")
+	}
+
+	classifier, err := loaded.BestOfClassifier()
+	if err != nil {
+		t.Fatalf("Failed to make a classifier: %v", err)
+	}
+	if classes, _ := classifier([]float64{0.2, 0.8}); len(classes) != 1 || classes[0] != "large" {
+		t.Errorf("Expected large but got %v", classes)
+	}
+}
+
+func TestNetwork_LoadWithoutMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	MakeNetwork(2, 1).Save(&buf)
+	if strings.Contains(buf.String(), "Metadata") {
+		t.Errorf("Expected no metadata in %s", buf.String())
+	}
+
+	net, err := LoadNetwork(&buf, "any", "names")
+	if err != nil || net.Metadata != nil {
+		t.Errorf("Expected a network without metadata but got %v, %v", net.Metadata, err)
+	}
+
+	if _, err := net.BestOfClassifier(); err == nil {
+		t.Error("Expected an error for a network without classes")
+	}
+}
+
+func TestMetadata_Validate(t *testing.T) {
+	net := MakeNetwork(2, 1)
+
+	for _, m := range []Metadata{
+		{Inputs: []string{"a"}},
+		{Outputs: []string{"a", "b"}},
+		{Classes: []string{"a", "b"}},
+		{Version: "2.0.0"},
+		{Version: "latest"},
+	} {
+		net.Metadata = &m
+		if err := net.Validate(); err == nil {
+			t.Errorf("Expected an error for %+v", m)
+		}
+	}
+
+	m := Metadata{Inputs: []string{"a", "b"}, Outputs: []string{"c"}, Version: "v1.9.3"}
+	net.Metadata = &m
+	if err := net.Validate(); err != nil {
+		t.Errorf("Expected valid metadata but got %v", err)
+	}
+}
+
+func TestHashTrainingData(t *testing.T) {
+	data := TrainingData{
+		{Inputs: []float64{1.0, 2.0}, Expected: []float64{0.0}},
+		{Inputs: []float64{3.0, 4.0}, Expected: []float64{1.0}},
+	}
+
+	hash := HashTrainingData(data)
+	if !strings.HasPrefix(hash, "sha256:") || hash != HashTrainingData(data) {
+		t.Errorf("Expected a stable sha256 hash but got %s", hash)
+	}
+
+	reordered := TrainingData{data[1], data[0]}
+	weighted := TrainingData{data[0], {Inputs: []float64{3.0, 4.0}, Expected: []float64{1.0}, Weight: 2.0}}
+	shifted := TrainingData{{Inputs: []float64{1.0}, Expected: []float64{2.0, 0.0}}, data[1]}
+	for _, other := range []TrainingData{reordered, weighted, shifted} {
+		if HashTrainingData(other) == hash {
+			t.Errorf("Expected a different hash for %v", other)
+		}
+	}
+}
+
+func TestMakeMetadata(t *testing.T) {
+	m := MakeMetadata()
+	if m.Version != Version || time.Since(m.Created) > time.Minute || m.Created.Location() != time.UTC {
+		t.Errorf("Unexpected metadata %+v", m)
+	}
+}
@@ -0,0 +1,194 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the prediction
+// latency histogram when no other buckets are given.
+var DefaultLatencyBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+// Metrics collects training and prediction metrics and serves them over HTTP in
+// the Prometheus text exposition format.  Register it with a Trainer using
+// Instrument and time predictions with Process or ObservePrediction.  Metrics
+// is safe for concurrent use, so it can be scraped while training runs.
+type Metrics struct {
+	mu               sync.Mutex
+	trainingStart    time.Time
+	iterations       int
+	trainingErrors   int
+	loss             float64
+	outputErrors     SquaredError
+	gradientNorm     float64
+	buckets          []float64
+	bucketCounts     []int
+	predictions      int
+	predictionSum    float64
+	predictionErrors int
+}
+
+// MakeMetrics creates a metrics collector.  The buckets are the upper bounds, in
+// seconds, of the prediction latency histogram and must be in increasing order;
+// if none are given DefaultLatencyBuckets is used.
+func MakeMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	return &Metrics{buckets: buckets, bucketCounts: make([]int, len(buckets))}
+}
+
+// Instrument registers callbacks on the trainer that record the loss, the error
+// for each output, the gradient norm and the number of iterations.
+func (m *Metrics) Instrument(t *Trainer) {
+	t.AddTrainingBeginHandler(func(t *Trainer) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.trainingStart = time.Now()
+	})
+
+	t.AddIterationEndHandler(func(t *Trainer, mse SquaredError, iter int, err error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.iterations++
+		if err != nil {
+			m.trainingErrors++
+			return
+		}
+
+		m.loss = t.Loss(mse)
+		m.outputErrors = append(SquaredError{}, mse...)
+		m.gradientNorm = t.GradientNorm()
+	})
+}
+
+// ObservePrediction records the time a prediction took and whether it failed.
+func (m *Metrics) ObservePrediction(duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seconds := duration.Seconds()
+	m.predictions++
+	m.predictionSum += seconds
+	if err != nil {
+		m.predictionErrors++
+	}
+
+	for idx, bound := range m.buckets {
+		if seconds <= bound {
+			m.bucketCounts[idx]++
+		}
+	}
+}
+
+// Process processes the inputs with the network and records the prediction.
+func (m *Metrics) Process(net *Network, inputs []float64) ([]float64, error) {
+	start := time.Now()
+	outputs, err := net.Process(inputs)
+	m.ObservePrediction(time.Since(start), err)
+	return outputs, err
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	format := func(v float64) string {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+
+	rate := 0.0
+	if !m.trainingStart.IsZero() {
+		if elapsed := time.Since(m.trainingStart).Seconds(); elapsed > 0 {
+			rate = float64(m.iterations) / elapsed
+		}
+	}
+
+	// write stops at the first error and keeps it, so the error returned is
+	// the one that cut the output short.
+	var total int64
+	var err error
+	write := func(text string, args ...any) {
+		if err != nil {
+			return
+		}
+		var n int
+		n, err = fmt.Fprintf(w, text, args...)
+		total += int64(n)
+	}
+
+	write("# HELP gofeedforward_training_iterations_total Training iterations completed.\n")
+	write("# TYPE gofeedforward_training_iterations_total counter\n")
+	write("gofeedforward_training_iterations_total %d\n", m.iterations)
+	write("# HELP gofeedforward_training_errors_total Training iterations that failed.\n")
+	write("# TYPE gofeedforward_training_errors_total counter\n")
+	write("gofeedforward_training_errors_total %d\n", m.trainingErrors)
+	write("# HELP gofeedforward_training_iterations_per_second Training iterations per second since training started.\n")
+	write("# TYPE gofeedforward_training_iterations_per_second gauge\n")
+	write("gofeedforward_training_iterations_per_second %s\n", format(rate))
+	write("# HELP gofeedforward_training_loss Training loss of the last iteration.\n")
+	write("# TYPE gofeedforward_training_loss gauge\n")
+	write("gofeedforward_training_loss %s\n", format(m.loss))
+	write("# HELP gofeedforward_training_output_error Mean squared error of each output in the last iteration.\n")
+	write("# TYPE gofeedforward_training_output_error gauge\n")
+	for idx, value := range m.outputErrors {
+		write("gofeedforward_training_output_error{output=\"%d\"} %s\n", idx, format(value))
+	}
+	write("# HELP gofeedforward_training_gradient_norm Gradient norm of the last iteration.\n")
+	write("# TYPE gofeedforward_training_gradient_norm gauge\n")
+	write("gofeedforward_training_gradient_norm %s\n", format(m.gradientNorm))
+
+	write("# HELP gofeedforward_prediction_duration_seconds Time taken to make a prediction.\n")
+	write("# TYPE gofeedforward_prediction_duration_seconds histogram\n")
+	for idx, bound := range m.buckets {
+		write("gofeedforward_prediction_duration_seconds_bucket{le=\"%s\"} %d\n", format(bound), m.bucketCounts[idx])
+	}
+	write("gofeedforward_prediction_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.predictions)
+	write("gofeedforward_prediction_duration_seconds_sum %s\n", format(m.predictionSum))
+	write("gofeedforward_prediction_duration_seconds_count %d\n", m.predictions)
+	write("# HELP gofeedforward_prediction_errors_total Predictions that failed.\n")
+	write("# TYPE gofeedforward_prediction_errors_total counter\n")
+	write("gofeedforward_prediction_errors_total %d\n", m.predictionErrors)
+
+	return total, err
+}
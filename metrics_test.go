@@ -0,0 +1,128 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T, url string) string {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Failed to scrape metrics: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type %s", resp.Header.Get("Content-Type"))
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestMetrics_Training(t *testing.T) {
+	metrics := MakeMetrics()
+	server := httptest.NewServer(metrics)
+	defer server.Close()
+
+	net := MakeNetwork(2, 3, 1)
+	net.Randomize()
+
+	trainer := Trainer{}
+	metrics.Instrument(&trainer)
+	trainer.AddSimpleStoppingCriteria(4, 0.0)
+	trainer.Train(&net, xorData())
+
+	body := scrape(t, server.URL)
+	for _, expected := range []string{
+		"gofeedforward_training_iterations_total 5\n",
+		"gofeedforward_training_errors_total 0\n",
+		"gofeedforward_training_output_error{output=\"0\"} ",
+		"# TYPE gofeedforward_training_loss gauge\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected metrics to contain %q but got:\n%s", expected, body)
+		}
+	}
+
+	if strings.Contains(body, "gofeedforward_training_gradient_norm 0\n") {
+		t.Errorf("Expected a gradient norm to be recorded")
+	}
+}
+
+func TestMetrics_Predictions(t *testing.T) {
+	metrics := MakeMetrics(0.001, 0.01)
+	server := httptest.NewServer(metrics)
+	defer server.Close()
+
+	net := MakeNetwork(2, 1)
+	metrics.Process(&net, []float64{1.0, 1.0})
+	metrics.Process(&net, []float64{1.0})
+	metrics.ObservePrediction(5*time.Millisecond, nil)
+
+	body := scrape(t, server.URL)
+	for _, expected := range []string{
+		"gofeedforward_prediction_duration_seconds_bucket{le=\"0.001\"} 2\n",
+		"gofeedforward_prediction_duration_seconds_bucket{le=\"0.01\"} 3\n",
+		"gofeedforward_prediction_duration_seconds_bucket{le=\"+Inf\"} 3\n",
+		"gofeedforward_prediction_duration_seconds_count 3\n",
+		"gofeedforward_prediction_errors_total 1\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected metrics to contain %q but got:\n%s", expected, body)
+		}
+	}
+}
+
+// failingWriter fails its first write and accepts the rest.
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes == 1 {
+		return 0, errTest
+	}
+	return len(p), nil
+}
+
+func TestMetrics_WriteToError(t *testing.T) {
+	w := &failingWriter{}
+	n, err := MakeMetrics().WriteTo(w)
+	if err != errTest || n != 0 || w.writes != 1 {
+		t.Errorf("Expected the first write error and nothing written but got %d bytes, %d writes, %v", n, w.writes, err)
+	}
+}
@@ -53,10 +53,20 @@ import "fmt"
 
 // Network represents a neural network.  It is composed of its layers and the
 // output from the last inputs presented.  The last output value is important
-// training but may also be useful in other contexts.
+// training but may also be useful in other contexts.  Only the layer weights
+// and the optional metadata are saved when a network is encoded as JSON.
 type Network struct {
-	Layers  []Layer
-	Outputs []float64
+	Layers   []Layer
+	Metadata *Metadata `json:",omitempty"`
+	Outputs  []float64 `json:"-"`
+}
+
+// Gradients is the result of propagating a gradient backward through a
+// network.  Weights holds one Core per layer, the same size as that layer's
+// weights, and Inputs holds the gradient with respect to each network input.
+type Gradients struct {
+	Weights []Core
+	Inputs  []float64
 }
 
 // BasicClassifier is the signature for a classifer that turns an array of
@@ -108,6 +118,69 @@ func (n *Network) Process(inputs []float64) ([]float64, error) {
 	return n.Outputs, nil
 }
 
+// Backward processes the inputs and then propagates the output gradient back
+// through the network.  The output gradient is the gradient of some error with
+// respect to each network output; for the squared error used by the Trainer
+// that is simply the output minus the expected value.  It returns the gradient
+// with respect to every weight in every layer and with respect to the inputs,
+// which makes it possible to write custom training loops or to examine how
+// sensitive the outputs are to each input.
+func (n *Network) Backward(inputs, outputGrad []float64) (Gradients, error) {
+	if _, err := n.Process(inputs); err != nil {
+		return Gradients{}, err
+	}
+	return n.backpropagate(outputGrad)
+}
+
+// backpropagate propagates the output gradient through the layers using the
+// inputs and outputs retained from the last call to Process.
+func (n *Network) backpropagate(outputGrad []float64) (Gradients, error) {
+	result := Gradients{Weights: make([]Core, len(n.Layers))}
+	grad := outputGrad
+	for idx := len(n.Layers) - 1; idx >= 0; idx-- {
+		weights, inputs, err := n.Layers[idx].Backward(grad)
+		if err != nil {
+			return Gradients{}, err
+		}
+		result.Weights[idx] = weights
+		grad = inputs
+	}
+	result.Inputs = grad
+	return result, nil
+}
+
+// Validate checks that the network has at least one layer, that every layer's
+// weights are rectangular, that each layer has one input (plus the bias) for
+// every output of the layer before it and that any metadata matches the
+// network.  It is useful after a network has been loaded from somewhere else.
+func (n Network) Validate() error {
+	if len(n.Layers) == 0 {
+		return fmt.Errorf("Network has no layers")
+	}
+
+	for idx, layer := range n.Layers {
+		if len(layer.Weights) == 0 || len(layer.Weights[0]) < 2 {
+			return fmt.Errorf("Layer %d has no weights", idx)
+		}
+
+		for _, row := range layer.Weights {
+			if len(row) != len(layer.Weights[0]) {
+				return fmt.Errorf("Layer %d has rows of different lengths: %d vs %d", idx, len(row), len(layer.Weights[0]))
+			}
+		}
+
+		if idx > 0 && layer.Weights.InputSize()-1 != n.Layers[idx-1].Weights.OutputSize() {
+			return fmt.Errorf("Layer %d expects %d inputs but layer %d has %d outputs", idx,
+				layer.Weights.InputSize()-1, idx-1, n.Layers[idx-1].Weights.OutputSize())
+		}
+	}
+
+	if n.Metadata != nil {
+		return n.Metadata.Validate(n)
+	}
+	return nil
+}
+
 // InputSize returns the network input size.  When presenting data
 // to the network, the array of values must be exactly this size.
 func (n Network) InputSize() int {
@@ -132,3 +132,89 @@ func TestMakeThresholdClassifier(t *testing.T) {
 		t.Errorf("Expected 'three' but got '%s'", cl[1])
 	}
 }
+
+func halfSquaredError(net *Network, inputs, expected []float64) float64 {
+	outputs, _ := net.Process(inputs)
+	sum := 0.0
+	for idx := range outputs {
+		sum += 0.5 * (outputs[idx] - expected[idx]) * (outputs[idx] - expected[idx])
+	}
+	return sum
+}
+
+func TestNetwork_Backward(t *testing.T) {
+	net := MakeNetwork(3, 4, 2)
+	net.Randomize()
+	inputs := []float64{0.2, 0.7, 0.4}
+	expected := []float64{0.9, 0.1}
+
+	outputs, _ := net.Process(inputs)
+	outputGrad := []float64{outputs[0] - expected[0], outputs[1] - expected[1]}
+
+	grads, err := net.Backward(inputs, outputGrad)
+	if err != nil {
+		t.Errorf("Failed to calculate gradients: %v", err)
+	}
+
+	if len(grads.Weights) != 2 || len(grads.Inputs) != 3 {
+		t.Errorf("Expected 2 weight gradients and 3 input gradients but got %d and %d",
+			len(grads.Weights), len(grads.Inputs))
+	}
+
+	const epsilon = 0.00001
+	for layerIdx, layer := range net.Layers {
+		for row := range layer.Weights {
+			for col := range layer.Weights[row] {
+				original := layer.Weights[row][col]
+				layer.Weights[row][col] = original + epsilon
+				plus := halfSquaredError(&net, inputs, expected)
+				layer.Weights[row][col] = original - epsilon
+				minus := halfSquaredError(&net, inputs, expected)
+				layer.Weights[row][col] = original
+
+				numeric := (plus - minus) / (2 * epsilon)
+				if outOfBoundsCheck(numeric, grads.Weights[layerIdx][row][col], 0.00001) {
+					t.Errorf("Layer %d weight [%d][%d] expected gradient %0.6f but got %0.6f",
+						layerIdx, row, col, numeric, grads.Weights[layerIdx][row][col])
+				}
+			}
+		}
+	}
+
+	for idx := range inputs {
+		shifted := append([]float64{}, inputs...)
+		shifted[idx] = inputs[idx] + epsilon
+		plus := halfSquaredError(&net, shifted, expected)
+		shifted[idx] = inputs[idx] - epsilon
+		minus := halfSquaredError(&net, shifted, expected)
+
+		numeric := (plus - minus) / (2 * epsilon)
+		if outOfBoundsCheck(numeric, grads.Inputs[idx], 0.00001) {
+			t.Errorf("Input %d expected gradient %0.6f but got %0.6f", idx, numeric, grads.Inputs[idx])
+		}
+	}
+}
+
+func TestNetwork_BackwardInvalidGradientSize(t *testing.T) {
+	net := MakeNetwork(2, 3, 1)
+
+	if _, err := net.Backward([]float64{1.0, 1.0}, []float64{1.0, 1.0}); err == nil {
+		t.Error("Expected error to be non nil")
+	}
+}
+
+func TestNetwork_Validate(t *testing.T) {
+	net := MakeNetwork(2, 3, 1)
+	if err := net.Validate(); err != nil {
+		t.Errorf("Expected a valid network but got %v", err)
+	}
+
+	net.Layers[1] = MakeLayer(4, 1)
+	if err := net.Validate(); err == nil {
+		t.Error("Expected an error for mismatched layers")
+	}
+
+	if err := (Network{}).Validate(); err == nil {
+		t.Error("Expected an error for a network without layers")
+	}
+}
@@ -0,0 +1,193 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package onnx exchanges networks with other runtimes in the ONNX format.  The
+// messages in onnx.proto are the subset of the ONNX format needed for fully
+// connected networks; regenerate onnx.pb.go with go generate after changing
+// it.
+package onnx
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative onnx.proto
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/DarcInc/gofeedforward"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// irVersion is the version of the ONNX format that goes with opsetVersion.
+	irVersion = 7
+
+	// opsetVersion is the version of the standard operators the graph uses.
+	opsetVersion = 13
+)
+
+// Exporter converts a Network into an ONNX model.  Each layer becomes a Gemm
+// node, or a MatMul node and an Add node if MatMul is true, followed by a
+// Sigmoid node.  The weights of each layer are split into a weight initializer
+// and a bias initializer that holds the bias column of the layer's Core.  The
+// graph has one input named "input" and one output named "output", both with a
+// batch dimension so a runtime can evaluate many examples at once.  Weights are
+// stored as 32 bit floats, which most runtimes expect, unless Double is true.
+type Exporter struct {
+	MatMul    bool
+	Double    bool
+	GraphName string
+	DocString string
+}
+
+// Model returns the ONNX model for the network.
+func (e Exporter) Model(net gofeedforward.Network) (*ModelProto, error) {
+	if err := net.Validate(); err != nil {
+		return nil, err
+	}
+
+	elemType := TensorProto_FLOAT
+	if e.Double {
+		elemType = TensorProto_DOUBLE
+	}
+
+	name := e.GraphName
+	if name == "" {
+		name = "gofeedforward"
+	}
+
+	graph := &GraphProto{
+		Name:   name,
+		Input:  []*ValueInfoProto{valueInfo("input", elemType, net.InputSize())},
+		Output: []*ValueInfoProto{valueInfo("output", elemType, net.OutputSize())},
+	}
+
+	current := "input"
+	for idx, layer := range net.Layers {
+		prefix := fmt.Sprintf("layer%d_", idx)
+		inputs, outputs := layer.Weights.InputSize()-1, layer.Weights.OutputSize()
+
+		bias := make([]float64, outputs)
+		for o := 0; o < outputs; o++ {
+			bias[o] = layer.Weights[o][inputs]
+		}
+
+		// Gemm multiplies by the transpose of the weights, which are stored
+		// one row per output just as the Core is.  MatMul needs one row per
+		// input instead.
+		weights := make([]float64, 0, inputs*outputs)
+		dims := []int64{int64(outputs), int64(inputs)}
+		if e.MatMul {
+			dims = []int64{int64(inputs), int64(outputs)}
+			for i := 0; i < inputs; i++ {
+				for o := 0; o < outputs; o++ {
+					weights = append(weights, layer.Weights[o][i])
+				}
+			}
+		} else {
+			for o := 0; o < outputs; o++ {
+				weights = append(weights, layer.Weights[o][:inputs]...)
+			}
+		}
+
+		graph.Initializer = append(graph.Initializer,
+			tensor(prefix+"weight", elemType, dims, weights),
+			tensor(prefix+"bias", elemType, []int64{int64(outputs)}, bias))
+
+		linear := prefix + "linear"
+		if e.MatMul {
+			graph.Node = append(graph.Node,
+				&NodeProto{Name: prefix + "matmul", OpType: "MatMul", Input: []string{current, prefix + "weight"}, Output: []string{prefix + "product"}},
+				&NodeProto{Name: prefix + "add", OpType: "Add", Input: []string{prefix + "product", prefix + "bias"}, Output: []string{linear}})
+		} else {
+			graph.Node = append(graph.Node, &NodeProto{
+				Name:      prefix + "gemm",
+				OpType:    "Gemm",
+				Input:     []string{current, prefix + "weight", prefix + "bias"},
+				Output:    []string{linear},
+				Attribute: []*AttributeProto{{Name: "transB", Type: AttributeProto_INT, I: 1}},
+			})
+		}
+
+		current = prefix + "output"
+		if idx == len(net.Layers)-1 {
+			current = "output"
+		}
+		graph.Node = append(graph.Node, &NodeProto{Name: prefix + "sigmoid", OpType: "Sigmoid", Input: []string{linear}, Output: []string{current}})
+	}
+
+	return &ModelProto{
+		IrVersion:    irVersion,
+		OpsetImport:  []*OperatorSetIdProto{{Version: opsetVersion}},
+		ProducerName: "gofeedforward",
+		DocString:    e.DocString,
+		Graph:        graph,
+	}, nil
+}
+
+// Write writes the network to w as an ONNX model.
+func (e Exporter) Write(w io.Writer, net gofeedforward.Network) error {
+	model, err := e.Model(net)
+	if err != nil {
+		return err
+	}
+
+	data, err := proto.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// valueInfo describes a graph input or output with a batch dimension named N.
+func valueInfo(name string, elemType TensorProto_DataType, size int) *ValueInfoProto {
+	shape := &TensorShapeProto{Dim: []*TensorShapeProto_Dimension{
+		{Value: &TensorShapeProto_Dimension_DimParam{DimParam: "N"}},
+		{Value: &TensorShapeProto_Dimension_DimValue{DimValue: int64(size)}},
+	}}
+
+	return &ValueInfoProto{
+		Name: name,
+		Type: &TypeProto{Value: &TypeProto_TensorType{TensorType: &TypeProto_Tensor{ElemType: int32(elemType), Shape: shape}}},
+	}
+}
+
+func tensor(name string, elemType TensorProto_DataType, dims []int64, values []float64) *TensorProto {
+	result := &TensorProto{Name: name, DataType: int32(elemType), Dims: dims}
+	if elemType == TensorProto_DOUBLE {
+		result.DoubleData = values
+		return result
+	}
+
+	result.FloatData = make([]float32, len(values))
+	for idx, value := range values {
+		result.FloatData[idx] = float32(value)
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package onnx
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/DarcInc/gofeedforward"
+)
+
+func testNetwork() gofeedforward.Network {
+	net := gofeedforward.MakeNetwork(3, 4, 2)
+	net.Randomize()
+	return net
+}
+
+// compareOutputs checks that two networks produce the same outputs, within
+// the tolerance, for a few inputs.
+func compareOutputs(t *testing.T, expected, actual gofeedforward.Network, tolerance float64) {
+	for _, inputs := range [][]float64{{0.0, 0.0, 0.0}, {1.0, -1.0, 0.5}, {0.3, 2.0, -0.7}} {
+		want, err := expected.Process(inputs)
+		if err != nil {
+			t.Fatalf("Failed to process: %v", err)
+		}
+
+		got, err := actual.Process(inputs)
+		if err != nil {
+			t.Fatalf("Failed to process with imported network: %v", err)
+		}
+
+		for idx := range want {
+			if math.Abs(want[idx]-got[idx]) > tolerance {
+				t.Errorf("Output %d for %v: expected %v but got %v", idx, inputs, want[idx], got[idx])
+			}
+		}
+	}
+}
+
+func TestExporter_Model(t *testing.T) {
+	net := testNetwork()
+	model, err := Exporter{}.Model(net)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	ops := []string{}
+	for _, node := range model.GetGraph().GetNode() {
+		ops = append(ops, node.GetOpType())
+	}
+	if len(ops) != 4 || ops[0] != "Gemm" || ops[1] != "Sigmoid" || ops[2] != "Gemm" || ops[3] != "Sigmoid" {
+		t.Errorf("Expected Gemm and Sigmoid nodes but got %v", ops)
+	}
+
+	initializers := model.GetGraph().GetInitializer()
+	if len(initializers) != 4 {
+		t.Fatalf("Expected 4 initializers but got %d", len(initializers))
+	}
+
+	weight, bias := initializers[0], initializers[1]
+	if len(weight.GetDims()) != 2 || weight.GetDims()[0] != 4 || weight.GetDims()[1] != 3 {
+		t.Errorf("Expected 4 x 3 weights but got %v", weight.GetDims())
+	}
+
+	for o := 0; o < 4; o++ {
+		if float32(net.Layers[0].Weights[o][3]) != bias.GetFloatData()[o] {
+			t.Errorf("Expected bias %d to be the bias column %v but got %v", o, net.Layers[0].Weights[o][3], bias.GetFloatData()[o])
+		}
+	}
+
+	if model.GetOpsetImport()[0].GetVersion() != opsetVersion || model.GetGraph().GetOutput()[0].GetName() != "output" {
+		t.Errorf("Unexpected model %v", model)
+	}
+
+	if _, err := (Exporter{}).Model(gofeedforward.Network{}); err == nil {
+		t.Errorf("Expected an error exporting an empty network")
+	}
+}
+
+func TestExporter_RoundTrip(t *testing.T) {
+	cases := map[string]struct {
+		exporter  Exporter
+		tolerance float64
+	}{
+		"gemm float":    {Exporter{}, 1e-6},
+		"gemm double":   {Exporter{Double: true}, 0.0},
+		"matmul float":  {Exporter{MatMul: true}, 1e-6},
+		"matmul double": {Exporter{MatMul: true, Double: true}, 0.0},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			net := testNetwork()
+
+			var buf bytes.Buffer
+			if err := c.exporter.Write(&buf, net); err != nil {
+				t.Fatalf("Failed to export: %v", err)
+			}
+
+			imported, err := Import(&buf)
+			if err != nil {
+				t.Fatalf("Failed to import: %v", err)
+			}
+
+			if imported.InputSize() != 3 || imported.OutputSize() != 2 || len(imported.Layers) != 2 {
+				t.Fatalf("Expected a 3, 4, 2 network but got %d layers", len(imported.Layers))
+			}
+			compareOutputs(t, net, imported, c.tolerance)
+		})
+	}
+}
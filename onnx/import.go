@@ -0,0 +1,362 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package onnx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/DarcInc/gofeedforward"
+	"google.golang.org/protobuf/proto"
+)
+
+// layerBuilder collects the weights and bias of a layer while its nodes are
+// read.  Weights has one row per output, as a Core does, but no bias column.
+type layerBuilder struct {
+	weights [][]float64
+	bias    []float64
+	output  string
+}
+
+// Import reads an ONNX model and converts it into a Network.
+func Import(r io.Reader) (gofeedforward.Network, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return gofeedforward.Network{}, err
+	}
+
+	model := &ModelProto{}
+	if err := proto.Unmarshal(data, model); err != nil {
+		return gofeedforward.Network{}, fmt.Errorf("Unable to read ONNX model: %v", err)
+	}
+	return ModelNetwork(model)
+}
+
+// activations lists the activation operators that are recognized so that they
+// can be reported as unsupported rather than as unknown operators.
+var activations = map[string]bool{
+	"Relu": true, "LeakyRelu": true, "PRelu": true, "Tanh": true, "Softmax": true, "LogSoftmax": true,
+	"Elu": true, "Selu": true, "Celu": true, "Gelu": true, "HardSigmoid": true, "HardSwish": true,
+	"Softplus": true, "Softsign": true, "Mish": true,
+}
+
+// ModelNetwork converts the graph of an ONNX model into a Network.  The graph
+// must be a chain of layers, each a Gemm node, or a MatMul node and an Add
+// node, followed by a Sigmoid node, with the weights and biases stored in
+// initializers.  Identity nodes, Dropout nodes and Flatten nodes on the two
+// dimensional inputs of a fully connected network do nothing and are skipped.
+// Any other operator, including activations other than Sigmoid, which is the
+// only activation a Network supports, is an error.
+func ModelNetwork(model *ModelProto) (gofeedforward.Network, error) {
+	graph := model.GetGraph()
+	if graph == nil {
+		return gofeedforward.Network{}, fmt.Errorf("ONNX model has no graph")
+	}
+
+	initializers := map[string]*TensorProto{}
+	for _, t := range graph.GetInitializer() {
+		initializers[t.GetName()] = t
+	}
+
+	current := ""
+	for _, input := range graph.GetInput() {
+		if _, ok := initializers[input.GetName()]; !ok {
+			current = input.GetName()
+			break
+		}
+	}
+	if current == "" {
+		return gofeedforward.Network{}, fmt.Errorf("ONNX graph has no input")
+	}
+
+	net := gofeedforward.Network{}
+	var pending *layerBuilder
+	for _, node := range graph.GetNode() {
+		if domain := node.GetDomain(); domain != "" && domain != "ai.onnx" {
+			return net, fmt.Errorf("Node %q: operator %s from domain %q is not supported", node.GetName(), node.GetOpType(), domain)
+		}
+
+		var err error
+		switch node.GetOpType() {
+		case "Identity", "Dropout", "Flatten":
+			if pending != nil {
+				return net, fmt.Errorf("Node %q: %s between a layer and its activation is not supported", node.GetName(), node.GetOpType())
+			}
+			if len(node.GetInput()) < 1 || node.GetInput()[0] != current || len(node.GetOutput()) < 1 {
+				return net, fmt.Errorf("Node %q: expected %s of %q", node.GetName(), node.GetOpType(), current)
+			}
+			if node.GetOpType() == "Flatten" {
+				for _, attr := range node.GetAttribute() {
+					if attr.GetName() == "axis" && attr.GetI() != 1 {
+						return net, fmt.Errorf("Node %q: Flatten with axis %d is not supported", node.GetName(), attr.GetI())
+					}
+				}
+			}
+			current = node.GetOutput()[0]
+
+		case "Gemm", "MatMul":
+			if pending != nil {
+				return net, fmt.Errorf("Node %q: %s follows a layer without an activation", node.GetName(), node.GetOpType())
+			}
+			if pending, err = readLinear(node, current, initializers); err != nil {
+				return net, err
+			}
+
+		case "Add":
+			if pending == nil {
+				return net, fmt.Errorf("Node %q: Add must follow a MatMul", node.GetName())
+			}
+			if err := readBias(node, pending, initializers); err != nil {
+				return net, err
+			}
+
+		case "Sigmoid":
+			if pending == nil || len(node.GetInput()) != 1 || node.GetInput()[0] != pending.output {
+				return net, fmt.Errorf("Node %q: Sigmoid must follow a Gemm, MatMul or Add", node.GetName())
+			}
+			if len(node.GetOutput()) != 1 {
+				return net, fmt.Errorf("Node %q: Sigmoid needs one output", node.GetName())
+			}
+			net.Layers = append(net.Layers, pending.layer())
+			current = node.GetOutput()[0]
+			pending = nil
+
+		default:
+			if activations[node.GetOpType()] {
+				return net, fmt.Errorf("Node %q: activation %s is not supported, a Network only uses Sigmoid", node.GetName(), node.GetOpType())
+			}
+			return net, fmt.Errorf("Node %q: unsupported operator %s", node.GetName(), node.GetOpType())
+		}
+	}
+
+	if pending != nil {
+		return net, fmt.Errorf("The last layer of the ONNX graph has no activation, but a Network applies Sigmoid to every layer")
+	}
+
+	if outputs := graph.GetOutput(); len(outputs) != 1 || outputs[0].GetName() != current {
+		return net, fmt.Errorf("ONNX graph output must be the output of the last layer, %q", current)
+	}
+
+	if err := net.Validate(); err != nil {
+		return net, err
+	}
+	return net, nil
+}
+
+// readLinear reads a Gemm or MatMul node that multiplies the current value by
+// a weight initializer.
+func readLinear(node *NodeProto, current string, initializers map[string]*TensorProto) (*layerBuilder, error) {
+	inputs := node.GetInput()
+	if len(inputs) < 2 || len(node.GetOutput()) != 1 {
+		return nil, fmt.Errorf("Node %q: %s needs at least two inputs and one output", node.GetName(), node.GetOpType())
+	}
+
+	if inputs[0] != current {
+		return nil, fmt.Errorf("Node %q: expected input %q but got %q", node.GetName(), current, inputs[0])
+	}
+
+	alpha, beta, transB := 1.0, 1.0, false
+	if node.GetOpType() == "Gemm" {
+		for _, attr := range node.GetAttribute() {
+			switch attr.GetName() {
+			case "alpha":
+				alpha = float64(attr.GetF())
+			case "beta":
+				beta = float64(attr.GetF())
+			case "transA":
+				if attr.GetI() != 0 {
+					return nil, fmt.Errorf("Node %q: Gemm with transA is not supported", node.GetName())
+				}
+			case "transB":
+				transB = attr.GetI() != 0
+			}
+		}
+	}
+
+	b, dims, err := initializer(node, inputs[1], initializers)
+	if err != nil {
+		return nil, err
+	}
+	if len(dims) != 2 {
+		return nil, fmt.Errorf("Node %q: weights %q must have two dimensions, not %d", node.GetName(), inputs[1], len(dims))
+	}
+
+	if dims[0] <= 0 || dims[1] <= 0 || dims[0] > int64(len(b)) || dims[1] > int64(len(b)) || dims[0]*dims[1] != int64(len(b)) {
+		return nil, fmt.Errorf("Node %q: weights %q have %d values, which does not match dimensions %v", node.GetName(), inputs[1], len(b), dims)
+	}
+
+	rows, cols := int(dims[0]), int(dims[1])
+	builder := &layerBuilder{output: node.GetOutput()[0]}
+	if transB {
+		builder.weights = make([][]float64, rows)
+		for o := range builder.weights {
+			builder.weights[o] = make([]float64, cols)
+			for i := range builder.weights[o] {
+				builder.weights[o][i] = alpha * b[o*cols+i]
+			}
+		}
+	} else {
+		builder.weights = make([][]float64, cols)
+		for o := range builder.weights {
+			builder.weights[o] = make([]float64, rows)
+			for i := range builder.weights[o] {
+				builder.weights[o][i] = alpha * b[i*cols+o]
+			}
+		}
+	}
+	builder.bias = make([]float64, len(builder.weights))
+
+	if node.GetOpType() == "Gemm" && len(inputs) > 2 && inputs[2] != "" {
+		c, _, err := initializer(node, inputs[2], initializers)
+		if err != nil {
+			return nil, err
+		}
+		if err := builder.addBias(c, beta); err != nil {
+			return nil, fmt.Errorf("Node %q: %v", node.GetName(), err)
+		}
+	}
+	return builder, nil
+}
+
+// readBias reads an Add node that adds a bias initializer to the output of a
+// MatMul.
+func readBias(node *NodeProto, pending *layerBuilder, initializers map[string]*TensorProto) error {
+	inputs := node.GetInput()
+	if len(inputs) != 2 || len(node.GetOutput()) != 1 {
+		return fmt.Errorf("Node %q: Add needs two inputs and one output", node.GetName())
+	}
+
+	name := inputs[1]
+	if inputs[1] == pending.output {
+		name = inputs[0]
+	} else if inputs[0] != pending.output {
+		return fmt.Errorf("Node %q: Add must take the output of the previous node, %q", node.GetName(), pending.output)
+	}
+
+	c, _, err := initializer(node, name, initializers)
+	if err != nil {
+		return err
+	}
+
+	if err := pending.addBias(c, 1.0); err != nil {
+		return fmt.Errorf("Node %q: %v", node.GetName(), err)
+	}
+	pending.output = node.GetOutput()[0]
+	return nil
+}
+
+// addBias adds the scaled values to the bias.  A single value is broadcast to
+// every output.
+func (b *layerBuilder) addBias(values []float64, scale float64) error {
+	if len(values) != 1 && len(values) != len(b.bias) {
+		return fmt.Errorf("bias has %d values for %d outputs", len(values), len(b.bias))
+	}
+
+	for o := range b.bias {
+		if len(values) == 1 {
+			b.bias[o] += scale * values[0]
+		} else {
+			b.bias[o] += scale * values[o]
+		}
+	}
+	return nil
+}
+
+// layer returns the layer with the bias as the last column of its Core.
+func (b *layerBuilder) layer() gofeedforward.Layer {
+	layer := gofeedforward.MakeLayer(len(b.weights[0]), len(b.weights))
+	for o, row := range b.weights {
+		copy(layer.Weights[o], row)
+		layer.Weights[o][len(row)] = b.bias[o]
+	}
+	return layer
+}
+
+// initializer returns the values and dimensions of the named initializer.
+func initializer(node *NodeProto, name string, initializers map[string]*TensorProto) ([]float64, []int64, error) {
+	t, ok := initializers[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("Node %q: %q is not an initializer", node.GetName(), name)
+	}
+
+	values, err := tensorValues(t)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Node %q: %v", node.GetName(), err)
+	}
+
+	size := int64(1)
+	for _, dim := range t.GetDims() {
+		size *= dim
+	}
+	if int64(len(values)) != size {
+		return nil, nil, fmt.Errorf("Node %q: initializer %q has %d values for dimensions %v", node.GetName(), name, len(values), t.GetDims())
+	}
+	return values, t.GetDims(), nil
+}
+
+// tensorValues returns the values of a float or double tensor, which may be
+// stored either in the typed fields or as little endian raw data.
+func tensorValues(t *TensorProto) ([]float64, error) {
+	raw := t.GetRawData()
+	switch TensorProto_DataType(t.GetDataType()) {
+	case TensorProto_FLOAT:
+		if len(raw) > 0 {
+			if len(raw)%4 != 0 {
+				return nil, fmt.Errorf("Tensor %q has %d bytes of raw data, which is not a whole number of floats", t.GetName(), len(raw))
+			}
+			values := make([]float64, len(raw)/4)
+			for idx := range values {
+				values[idx] = float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[idx*4:])))
+			}
+			return values, nil
+		}
+
+		values := make([]float64, len(t.GetFloatData()))
+		for idx, value := range t.GetFloatData() {
+			values[idx] = float64(value)
+		}
+		return values, nil
+
+	case TensorProto_DOUBLE:
+		if len(raw) > 0 {
+			if len(raw)%8 != 0 {
+				return nil, fmt.Errorf("Tensor %q has %d bytes of raw data, which is not a whole number of doubles", t.GetName(), len(raw))
+			}
+			values := make([]float64, len(raw)/8)
+			for idx := range values {
+				values[idx] = math.Float64frombits(binary.LittleEndian.Uint64(raw[idx*8:]))
+			}
+			return values, nil
+		}
+		return t.GetDoubleData(), nil
+	}
+	return nil, fmt.Errorf("Tensor %q has unsupported type %s", t.GetName(), TensorProto_DataType(t.GetDataType()))
+}
@@ -0,0 +1,186 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package onnx
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+func rawFloats(values ...float32) []byte {
+	raw := make([]byte, 4*len(values))
+	for idx, value := range values {
+		binary.LittleEndian.PutUint32(raw[idx*4:], math.Float32bits(value))
+	}
+	return raw
+}
+
+func TestModelNetwork_Errors(t *testing.T) {
+	cases := map[string]func(*ModelProto){
+		"no graph": func(m *ModelProto) { m.Graph = nil },
+		"unsupported op": func(m *ModelProto) {
+			m.Graph.Node[1].OpType = "Relu"
+		},
+		"no activation": func(m *ModelProto) {
+			m.Graph.Node = m.Graph.Node[:3]
+		},
+		"missing weights": func(m *ModelProto) {
+			m.Graph.Initializer = m.Graph.Initializer[1:]
+		},
+		"transA": func(m *ModelProto) {
+			m.Graph.Node[0].Attribute = append(m.Graph.Node[0].Attribute, &AttributeProto{Name: "transA", I: 1})
+		},
+		"integer weights": func(m *ModelProto) {
+			m.Graph.Initializer[0].DataType = int32(TensorProto_INT64)
+		},
+	}
+
+	for name, change := range cases {
+		model, err := Exporter{}.Model(testNetwork())
+		if err != nil {
+			t.Fatalf("Failed to export: %v", err)
+		}
+
+		change(model)
+		if _, err := ModelNetwork(model); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestModelNetwork_Gemm(t *testing.T) {
+	model := &ModelProto{Graph: &GraphProto{
+		Input:  []*ValueInfoProto{{Name: "x"}},
+		Output: []*ValueInfoProto{{Name: "y"}},
+		Initializer: []*TensorProto{
+			{Name: "w", DataType: int32(TensorProto_DOUBLE), Dims: []int64{2, 1}, DoubleData: []float64{1.0, 2.0}},
+			{Name: "b", DataType: int32(TensorProto_DOUBLE), Dims: []int64{1}, DoubleData: []float64{0.5}},
+		},
+		Node: []*NodeProto{
+			{OpType: "Gemm", Input: []string{"x", "w", "b"}, Output: []string{"z"},
+				Attribute: []*AttributeProto{{Name: "alpha", F: 2.0}, {Name: "beta", F: 3.0}}},
+			{OpType: "Sigmoid", Input: []string{"z"}, Output: []string{"y"}},
+		},
+	}}
+
+	net, err := ModelNetwork(model)
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	weights := net.Layers[0].Weights[0]
+	if len(weights) != 3 || weights[0] != 2.0 || weights[1] != 4.0 || weights[2] != 1.5 {
+		t.Errorf("Expected weights [2 4 1.5] but got %v", weights)
+	}
+
+	model.Graph.Output[0].Name = "z"
+	if _, err := ModelNetwork(model); err == nil || !strings.Contains(err.Error(), "output") {
+		t.Errorf("Expected an error for the wrong graph output but got %v", err)
+	}
+}
+
+// TestModelNetwork_Torch imports a graph laid out the way PyTorch exports a
+// linear layer, with the weights as raw data and a Flatten on the input.
+func TestModelNetwork_Torch(t *testing.T) {
+	model := &ModelProto{Graph: &GraphProto{
+		Input:  []*ValueInfoProto{{Name: "input.1"}, {Name: "fc.weight"}},
+		Output: []*ValueInfoProto{{Name: "9"}},
+		Initializer: []*TensorProto{
+			{Name: "fc.weight", DataType: int32(TensorProto_FLOAT), Dims: []int64{2, 3}, RawData: rawFloats(1, 2, 3, 4, 5, 6)},
+			{Name: "fc.bias", DataType: int32(TensorProto_FLOAT), Dims: []int64{2}, RawData: rawFloats(-1, 1)},
+		},
+		Node: []*NodeProto{
+			{Name: "Flatten_0", OpType: "Flatten", Input: []string{"input.1"}, Output: []string{"7"},
+				Attribute: []*AttributeProto{{Name: "axis", Type: AttributeProto_INT, I: 1}}},
+			{Name: "Gemm_1", OpType: "Gemm", Input: []string{"7", "fc.weight", "fc.bias"}, Output: []string{"8"},
+				Attribute: []*AttributeProto{{Name: "transB", Type: AttributeProto_INT, I: 1}}},
+			{Name: "Sigmoid_2", OpType: "Sigmoid", Input: []string{"8"}, Output: []string{"9"}},
+		},
+	}}
+
+	net, err := ModelNetwork(model)
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	expected := [][]float64{{1, 2, 3, -1}, {4, 5, 6, 1}}
+	for o, row := range expected {
+		for i, value := range row {
+			if net.Layers[0].Weights[o][i] != value {
+				t.Errorf("Expected weight %d, %d to be %v but got %v", o, i, value, net.Layers[0].Weights[o][i])
+			}
+		}
+	}
+
+	model.Graph.Initializer[0].RawData = model.Graph.Initializer[0].RawData[1:]
+	if _, err := ModelNetwork(model); err == nil || !strings.Contains(err.Error(), "raw data") {
+		t.Errorf("Expected an error for truncated raw data but got %v", err)
+	}
+}
+
+func TestModelNetwork_Unsupported(t *testing.T) {
+	cases := map[string]struct {
+		change  func(*ModelProto)
+		message string
+	}{
+		"relu":   {func(m *ModelProto) { m.Graph.Node[1].OpType = "Relu" }, "activation Relu is not supported"},
+		"conv":   {func(m *ModelProto) { m.Graph.Node[0].OpType = "Conv" }, "unsupported operator Conv"},
+		"domain": {func(m *ModelProto) { m.Graph.Node[1].Domain = "com.microsoft" }, "domain"},
+		"regression": {func(m *ModelProto) {
+			m.Graph.Node = m.Graph.Node[:3]
+			m.Graph.Node[2].Output[0] = "output"
+		}, "no activation"},
+		"sigmoid without output": {func(m *ModelProto) { m.Graph.Node[1].Output = nil }, "one output"},
+		"empty weights": {func(m *ModelProto) {
+			m.Graph.Initializer[0].Dims = []int64{0, 3}
+			m.Graph.Initializer[0].FloatData = nil
+			m.Graph.Initializer[0].DoubleData = nil
+		}, "dimensions"},
+		"negative dims": {func(m *ModelProto) { m.Graph.Initializer[0].Dims = []int64{-2, -3} }, "dimensions"},
+		"short weights": {func(m *ModelProto) { m.Graph.Initializer[0].Dims[0]++ }, "dimensions"},
+		"flatten axis": {func(m *ModelProto) {
+			m.Graph.Node = append([]*NodeProto{{OpType: "Flatten", Input: []string{"input"}, Output: []string{"input"},
+				Attribute: []*AttributeProto{{Name: "axis", I: 2}}}}, m.Graph.Node...)
+		}, "axis"},
+	}
+
+	for name, c := range cases {
+		model, err := Exporter{}.Model(testNetwork())
+		if err != nil {
+			t.Fatalf("Failed to export: %v", err)
+		}
+
+		c.change(model)
+		if _, err := ModelNetwork(model); err == nil || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("%s: expected an error containing %q but got %v", name, c.message, err)
+		}
+	}
+}
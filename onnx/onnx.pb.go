@@ -0,0 +1,1446 @@
+//
+//BSD 2-Clause License
+//
+//Copyright (c) 2016, Darc Inc
+//All rights reserved.
+//
+//Redistribution and use in source and binary forms, with or without
+//modification, are permitted provided that the following conditions are met:
+//
+// Redistributions of source code must retain the above copyright notice, this
+//list of conditions and the following disclaimer.
+//
+// Redistributions in binary form must reproduce the above copyright notice,
+//this list of conditions and the following disclaimer in the documentation
+//and/or other materials provided with the distribution.
+//
+//THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+//AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+//IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+//DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+//FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+//DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+//SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+//CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+//OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+//OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+// The subset of the ONNX model format needed to exchange fully connected
+// networks.  Message and field numbers follow onnx.proto3 from the ONNX
+// project, so models written with these messages are read by any ONNX runtime
+// and fields this file leaves out are skipped when reading other models.  The
+// protobuf package differs from the ONNX one so the messages can be linked
+// into a program alongside the official ones.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: onnx.proto
+
+package onnx
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AttributeProto_AttributeType int32
+
+const (
+	AttributeProto_UNDEFINED AttributeProto_AttributeType = 0
+	AttributeProto_FLOAT     AttributeProto_AttributeType = 1
+	AttributeProto_INT       AttributeProto_AttributeType = 2
+	AttributeProto_STRING    AttributeProto_AttributeType = 3
+	AttributeProto_TENSOR    AttributeProto_AttributeType = 4
+	AttributeProto_GRAPH     AttributeProto_AttributeType = 5
+	AttributeProto_FLOATS    AttributeProto_AttributeType = 6
+	AttributeProto_INTS      AttributeProto_AttributeType = 7
+	AttributeProto_STRINGS   AttributeProto_AttributeType = 8
+	AttributeProto_TENSORS   AttributeProto_AttributeType = 9
+	AttributeProto_GRAPHS    AttributeProto_AttributeType = 10
+)
+
+// Enum value maps for AttributeProto_AttributeType.
+var (
+	AttributeProto_AttributeType_name = map[int32]string{
+		0:  "UNDEFINED",
+		1:  "FLOAT",
+		2:  "INT",
+		3:  "STRING",
+		4:  "TENSOR",
+		5:  "GRAPH",
+		6:  "FLOATS",
+		7:  "INTS",
+		8:  "STRINGS",
+		9:  "TENSORS",
+		10: "GRAPHS",
+	}
+	AttributeProto_AttributeType_value = map[string]int32{
+		"UNDEFINED": 0,
+		"FLOAT":     1,
+		"INT":       2,
+		"STRING":    3,
+		"TENSOR":    4,
+		"GRAPH":     5,
+		"FLOATS":    6,
+		"INTS":      7,
+		"STRINGS":   8,
+		"TENSORS":   9,
+		"GRAPHS":    10,
+	}
+)
+
+func (x AttributeProto_AttributeType) Enum() *AttributeProto_AttributeType {
+	p := new(AttributeProto_AttributeType)
+	*p = x
+	return p
+}
+
+func (x AttributeProto_AttributeType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AttributeProto_AttributeType) Descriptor() protoreflect.EnumDescriptor {
+	return file_onnx_proto_enumTypes[0].Descriptor()
+}
+
+func (AttributeProto_AttributeType) Type() protoreflect.EnumType {
+	return &file_onnx_proto_enumTypes[0]
+}
+
+func (x AttributeProto_AttributeType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AttributeProto_AttributeType.Descriptor instead.
+func (AttributeProto_AttributeType) EnumDescriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{0, 0}
+}
+
+type TensorProto_DataType int32
+
+const (
+	TensorProto_UNDEFINED  TensorProto_DataType = 0
+	TensorProto_FLOAT      TensorProto_DataType = 1
+	TensorProto_UINT8      TensorProto_DataType = 2
+	TensorProto_INT8       TensorProto_DataType = 3
+	TensorProto_UINT16     TensorProto_DataType = 4
+	TensorProto_INT16      TensorProto_DataType = 5
+	TensorProto_INT32      TensorProto_DataType = 6
+	TensorProto_INT64      TensorProto_DataType = 7
+	TensorProto_STRING     TensorProto_DataType = 8
+	TensorProto_BOOL       TensorProto_DataType = 9
+	TensorProto_FLOAT16    TensorProto_DataType = 10
+	TensorProto_DOUBLE     TensorProto_DataType = 11
+	TensorProto_UINT32     TensorProto_DataType = 12
+	TensorProto_UINT64     TensorProto_DataType = 13
+	TensorProto_COMPLEX64  TensorProto_DataType = 14
+	TensorProto_COMPLEX128 TensorProto_DataType = 15
+	TensorProto_BFLOAT16   TensorProto_DataType = 16
+)
+
+// Enum value maps for TensorProto_DataType.
+var (
+	TensorProto_DataType_name = map[int32]string{
+		0:  "UNDEFINED",
+		1:  "FLOAT",
+		2:  "UINT8",
+		3:  "INT8",
+		4:  "UINT16",
+		5:  "INT16",
+		6:  "INT32",
+		7:  "INT64",
+		8:  "STRING",
+		9:  "BOOL",
+		10: "FLOAT16",
+		11: "DOUBLE",
+		12: "UINT32",
+		13: "UINT64",
+		14: "COMPLEX64",
+		15: "COMPLEX128",
+		16: "BFLOAT16",
+	}
+	TensorProto_DataType_value = map[string]int32{
+		"UNDEFINED":  0,
+		"FLOAT":      1,
+		"UINT8":      2,
+		"INT8":       3,
+		"UINT16":     4,
+		"INT16":      5,
+		"INT32":      6,
+		"INT64":      7,
+		"STRING":     8,
+		"BOOL":       9,
+		"FLOAT16":    10,
+		"DOUBLE":     11,
+		"UINT32":     12,
+		"UINT64":     13,
+		"COMPLEX64":  14,
+		"COMPLEX128": 15,
+		"BFLOAT16":   16,
+	}
+)
+
+func (x TensorProto_DataType) Enum() *TensorProto_DataType {
+	p := new(TensorProto_DataType)
+	*p = x
+	return p
+}
+
+func (x TensorProto_DataType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TensorProto_DataType) Descriptor() protoreflect.EnumDescriptor {
+	return file_onnx_proto_enumTypes[1].Descriptor()
+}
+
+func (TensorProto_DataType) Type() protoreflect.EnumType {
+	return &file_onnx_proto_enumTypes[1]
+}
+
+func (x TensorProto_DataType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TensorProto_DataType.Descriptor instead.
+func (TensorProto_DataType) EnumDescriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{6, 0}
+}
+
+type AttributeProto struct {
+	state         protoimpl.MessageState       `protogen:"open.v1"`
+	Name          string                       `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	RefAttrName   string                       `protobuf:"bytes,21,opt,name=ref_attr_name,json=refAttrName,proto3" json:"ref_attr_name,omitempty"`
+	DocString     string                       `protobuf:"bytes,13,opt,name=doc_string,json=docString,proto3" json:"doc_string,omitempty"`
+	Type          AttributeProto_AttributeType `protobuf:"varint,20,opt,name=type,proto3,enum=gofeedforward.onnx.AttributeProto_AttributeType" json:"type,omitempty"`
+	F             float32                      `protobuf:"fixed32,2,opt,name=f,proto3" json:"f,omitempty"`
+	I             int64                        `protobuf:"varint,3,opt,name=i,proto3" json:"i,omitempty"`
+	S             []byte                       `protobuf:"bytes,4,opt,name=s,proto3" json:"s,omitempty"`
+	T             *TensorProto                 `protobuf:"bytes,5,opt,name=t,proto3" json:"t,omitempty"`
+	G             *GraphProto                  `protobuf:"bytes,6,opt,name=g,proto3" json:"g,omitempty"`
+	Floats        []float32                    `protobuf:"fixed32,7,rep,packed,name=floats,proto3" json:"floats,omitempty"`
+	Ints          []int64                      `protobuf:"varint,8,rep,packed,name=ints,proto3" json:"ints,omitempty"`
+	Strings       [][]byte                     `protobuf:"bytes,9,rep,name=strings,proto3" json:"strings,omitempty"`
+	Tensors       []*TensorProto               `protobuf:"bytes,10,rep,name=tensors,proto3" json:"tensors,omitempty"`
+	Graphs        []*GraphProto                `protobuf:"bytes,11,rep,name=graphs,proto3" json:"graphs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttributeProto) Reset() {
+	*x = AttributeProto{}
+	mi := &file_onnx_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttributeProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeProto) ProtoMessage() {}
+
+func (x *AttributeProto) ProtoReflect() protoreflect.Message {
+	mi := &file_onnx_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeProto.ProtoReflect.Descriptor instead.
+func (*AttributeProto) Descriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AttributeProto) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AttributeProto) GetRefAttrName() string {
+	if x != nil {
+		return x.RefAttrName
+	}
+	return ""
+}
+
+func (x *AttributeProto) GetDocString() string {
+	if x != nil {
+		return x.DocString
+	}
+	return ""
+}
+
+func (x *AttributeProto) GetType() AttributeProto_AttributeType {
+	if x != nil {
+		return x.Type
+	}
+	return AttributeProto_UNDEFINED
+}
+
+func (x *AttributeProto) GetF() float32 {
+	if x != nil {
+		return x.F
+	}
+	return 0
+}
+
+func (x *AttributeProto) GetI() int64 {
+	if x != nil {
+		return x.I
+	}
+	return 0
+}
+
+func (x *AttributeProto) GetS() []byte {
+	if x != nil {
+		return x.S
+	}
+	return nil
+}
+
+func (x *AttributeProto) GetT() *TensorProto {
+	if x != nil {
+		return x.T
+	}
+	return nil
+}
+
+func (x *AttributeProto) GetG() *GraphProto {
+	if x != nil {
+		return x.G
+	}
+	return nil
+}
+
+func (x *AttributeProto) GetFloats() []float32 {
+	if x != nil {
+		return x.Floats
+	}
+	return nil
+}
+
+func (x *AttributeProto) GetInts() []int64 {
+	if x != nil {
+		return x.Ints
+	}
+	return nil
+}
+
+func (x *AttributeProto) GetStrings() [][]byte {
+	if x != nil {
+		return x.Strings
+	}
+	return nil
+}
+
+func (x *AttributeProto) GetTensors() []*TensorProto {
+	if x != nil {
+		return x.Tensors
+	}
+	return nil
+}
+
+func (x *AttributeProto) GetGraphs() []*GraphProto {
+	if x != nil {
+		return x.Graphs
+	}
+	return nil
+}
+
+type ValueInfoProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          *TypeProto             `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	DocString     string                 `protobuf:"bytes,3,opt,name=doc_string,json=docString,proto3" json:"doc_string,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValueInfoProto) Reset() {
+	*x = ValueInfoProto{}
+	mi := &file_onnx_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValueInfoProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValueInfoProto) ProtoMessage() {}
+
+func (x *ValueInfoProto) ProtoReflect() protoreflect.Message {
+	mi := &file_onnx_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValueInfoProto.ProtoReflect.Descriptor instead.
+func (*ValueInfoProto) Descriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ValueInfoProto) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ValueInfoProto) GetType() *TypeProto {
+	if x != nil {
+		return x.Type
+	}
+	return nil
+}
+
+func (x *ValueInfoProto) GetDocString() string {
+	if x != nil {
+		return x.DocString
+	}
+	return ""
+}
+
+type NodeProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Input         []string               `protobuf:"bytes,1,rep,name=input,proto3" json:"input,omitempty"`
+	Output        []string               `protobuf:"bytes,2,rep,name=output,proto3" json:"output,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	OpType        string                 `protobuf:"bytes,4,opt,name=op_type,json=opType,proto3" json:"op_type,omitempty"`
+	Domain        string                 `protobuf:"bytes,7,opt,name=domain,proto3" json:"domain,omitempty"`
+	Attribute     []*AttributeProto      `protobuf:"bytes,5,rep,name=attribute,proto3" json:"attribute,omitempty"`
+	DocString     string                 `protobuf:"bytes,6,opt,name=doc_string,json=docString,proto3" json:"doc_string,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeProto) Reset() {
+	*x = NodeProto{}
+	mi := &file_onnx_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeProto) ProtoMessage() {}
+
+func (x *NodeProto) ProtoReflect() protoreflect.Message {
+	mi := &file_onnx_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeProto.ProtoReflect.Descriptor instead.
+func (*NodeProto) Descriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NodeProto) GetInput() []string {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+func (x *NodeProto) GetOutput() []string {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *NodeProto) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NodeProto) GetOpType() string {
+	if x != nil {
+		return x.OpType
+	}
+	return ""
+}
+
+func (x *NodeProto) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *NodeProto) GetAttribute() []*AttributeProto {
+	if x != nil {
+		return x.Attribute
+	}
+	return nil
+}
+
+func (x *NodeProto) GetDocString() string {
+	if x != nil {
+		return x.DocString
+	}
+	return ""
+}
+
+type ModelProto struct {
+	state           protoimpl.MessageState    `protogen:"open.v1"`
+	IrVersion       int64                     `protobuf:"varint,1,opt,name=ir_version,json=irVersion,proto3" json:"ir_version,omitempty"`
+	OpsetImport     []*OperatorSetIdProto     `protobuf:"bytes,8,rep,name=opset_import,json=opsetImport,proto3" json:"opset_import,omitempty"`
+	ProducerName    string                    `protobuf:"bytes,2,opt,name=producer_name,json=producerName,proto3" json:"producer_name,omitempty"`
+	ProducerVersion string                    `protobuf:"bytes,3,opt,name=producer_version,json=producerVersion,proto3" json:"producer_version,omitempty"`
+	Domain          string                    `protobuf:"bytes,4,opt,name=domain,proto3" json:"domain,omitempty"`
+	ModelVersion    int64                     `protobuf:"varint,5,opt,name=model_version,json=modelVersion,proto3" json:"model_version,omitempty"`
+	DocString       string                    `protobuf:"bytes,6,opt,name=doc_string,json=docString,proto3" json:"doc_string,omitempty"`
+	Graph           *GraphProto               `protobuf:"bytes,7,opt,name=graph,proto3" json:"graph,omitempty"`
+	MetadataProps   []*StringStringEntryProto `protobuf:"bytes,14,rep,name=metadata_props,json=metadataProps,proto3" json:"metadata_props,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ModelProto) Reset() {
+	*x = ModelProto{}
+	mi := &file_onnx_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModelProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelProto) ProtoMessage() {}
+
+func (x *ModelProto) ProtoReflect() protoreflect.Message {
+	mi := &file_onnx_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelProto.ProtoReflect.Descriptor instead.
+func (*ModelProto) Descriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ModelProto) GetIrVersion() int64 {
+	if x != nil {
+		return x.IrVersion
+	}
+	return 0
+}
+
+func (x *ModelProto) GetOpsetImport() []*OperatorSetIdProto {
+	if x != nil {
+		return x.OpsetImport
+	}
+	return nil
+}
+
+func (x *ModelProto) GetProducerName() string {
+	if x != nil {
+		return x.ProducerName
+	}
+	return ""
+}
+
+func (x *ModelProto) GetProducerVersion() string {
+	if x != nil {
+		return x.ProducerVersion
+	}
+	return ""
+}
+
+func (x *ModelProto) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *ModelProto) GetModelVersion() int64 {
+	if x != nil {
+		return x.ModelVersion
+	}
+	return 0
+}
+
+func (x *ModelProto) GetDocString() string {
+	if x != nil {
+		return x.DocString
+	}
+	return ""
+}
+
+func (x *ModelProto) GetGraph() *GraphProto {
+	if x != nil {
+		return x.Graph
+	}
+	return nil
+}
+
+func (x *ModelProto) GetMetadataProps() []*StringStringEntryProto {
+	if x != nil {
+		return x.MetadataProps
+	}
+	return nil
+}
+
+type StringStringEntryProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StringStringEntryProto) Reset() {
+	*x = StringStringEntryProto{}
+	mi := &file_onnx_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StringStringEntryProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StringStringEntryProto) ProtoMessage() {}
+
+func (x *StringStringEntryProto) ProtoReflect() protoreflect.Message {
+	mi := &file_onnx_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StringStringEntryProto.ProtoReflect.Descriptor instead.
+func (*StringStringEntryProto) Descriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StringStringEntryProto) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *StringStringEntryProto) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type GraphProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          []*NodeProto           `protobuf:"bytes,1,rep,name=node,proto3" json:"node,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Initializer   []*TensorProto         `protobuf:"bytes,5,rep,name=initializer,proto3" json:"initializer,omitempty"`
+	DocString     string                 `protobuf:"bytes,10,opt,name=doc_string,json=docString,proto3" json:"doc_string,omitempty"`
+	Input         []*ValueInfoProto      `protobuf:"bytes,11,rep,name=input,proto3" json:"input,omitempty"`
+	Output        []*ValueInfoProto      `protobuf:"bytes,12,rep,name=output,proto3" json:"output,omitempty"`
+	ValueInfo     []*ValueInfoProto      `protobuf:"bytes,13,rep,name=value_info,json=valueInfo,proto3" json:"value_info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GraphProto) Reset() {
+	*x = GraphProto{}
+	mi := &file_onnx_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GraphProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GraphProto) ProtoMessage() {}
+
+func (x *GraphProto) ProtoReflect() protoreflect.Message {
+	mi := &file_onnx_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GraphProto.ProtoReflect.Descriptor instead.
+func (*GraphProto) Descriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GraphProto) GetNode() []*NodeProto {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *GraphProto) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GraphProto) GetInitializer() []*TensorProto {
+	if x != nil {
+		return x.Initializer
+	}
+	return nil
+}
+
+func (x *GraphProto) GetDocString() string {
+	if x != nil {
+		return x.DocString
+	}
+	return ""
+}
+
+func (x *GraphProto) GetInput() []*ValueInfoProto {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+func (x *GraphProto) GetOutput() []*ValueInfoProto {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *GraphProto) GetValueInfo() []*ValueInfoProto {
+	if x != nil {
+		return x.ValueInfo
+	}
+	return nil
+}
+
+type TensorProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Dims          []int64                `protobuf:"varint,1,rep,packed,name=dims,proto3" json:"dims,omitempty"`
+	DataType      int32                  `protobuf:"varint,2,opt,name=data_type,json=dataType,proto3" json:"data_type,omitempty"`
+	FloatData     []float32              `protobuf:"fixed32,4,rep,packed,name=float_data,json=floatData,proto3" json:"float_data,omitempty"`
+	Int32Data     []int32                `protobuf:"varint,5,rep,packed,name=int32_data,json=int32Data,proto3" json:"int32_data,omitempty"`
+	StringData    [][]byte               `protobuf:"bytes,6,rep,name=string_data,json=stringData,proto3" json:"string_data,omitempty"`
+	Int64Data     []int64                `protobuf:"varint,7,rep,packed,name=int64_data,json=int64Data,proto3" json:"int64_data,omitempty"`
+	Name          string                 `protobuf:"bytes,8,opt,name=name,proto3" json:"name,omitempty"`
+	DocString     string                 `protobuf:"bytes,12,opt,name=doc_string,json=docString,proto3" json:"doc_string,omitempty"`
+	RawData       []byte                 `protobuf:"bytes,9,opt,name=raw_data,json=rawData,proto3" json:"raw_data,omitempty"`
+	DoubleData    []float64              `protobuf:"fixed64,10,rep,packed,name=double_data,json=doubleData,proto3" json:"double_data,omitempty"`
+	Uint64Data    []uint64               `protobuf:"varint,11,rep,packed,name=uint64_data,json=uint64Data,proto3" json:"uint64_data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TensorProto) Reset() {
+	*x = TensorProto{}
+	mi := &file_onnx_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TensorProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TensorProto) ProtoMessage() {}
+
+func (x *TensorProto) ProtoReflect() protoreflect.Message {
+	mi := &file_onnx_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TensorProto.ProtoReflect.Descriptor instead.
+func (*TensorProto) Descriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TensorProto) GetDims() []int64 {
+	if x != nil {
+		return x.Dims
+	}
+	return nil
+}
+
+func (x *TensorProto) GetDataType() int32 {
+	if x != nil {
+		return x.DataType
+	}
+	return 0
+}
+
+func (x *TensorProto) GetFloatData() []float32 {
+	if x != nil {
+		return x.FloatData
+	}
+	return nil
+}
+
+func (x *TensorProto) GetInt32Data() []int32 {
+	if x != nil {
+		return x.Int32Data
+	}
+	return nil
+}
+
+func (x *TensorProto) GetStringData() [][]byte {
+	if x != nil {
+		return x.StringData
+	}
+	return nil
+}
+
+func (x *TensorProto) GetInt64Data() []int64 {
+	if x != nil {
+		return x.Int64Data
+	}
+	return nil
+}
+
+func (x *TensorProto) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TensorProto) GetDocString() string {
+	if x != nil {
+		return x.DocString
+	}
+	return ""
+}
+
+func (x *TensorProto) GetRawData() []byte {
+	if x != nil {
+		return x.RawData
+	}
+	return nil
+}
+
+func (x *TensorProto) GetDoubleData() []float64 {
+	if x != nil {
+		return x.DoubleData
+	}
+	return nil
+}
+
+func (x *TensorProto) GetUint64Data() []uint64 {
+	if x != nil {
+		return x.Uint64Data
+	}
+	return nil
+}
+
+type TensorShapeProto struct {
+	state         protoimpl.MessageState        `protogen:"open.v1"`
+	Dim           []*TensorShapeProto_Dimension `protobuf:"bytes,1,rep,name=dim,proto3" json:"dim,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TensorShapeProto) Reset() {
+	*x = TensorShapeProto{}
+	mi := &file_onnx_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TensorShapeProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TensorShapeProto) ProtoMessage() {}
+
+func (x *TensorShapeProto) ProtoReflect() protoreflect.Message {
+	mi := &file_onnx_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TensorShapeProto.ProtoReflect.Descriptor instead.
+func (*TensorShapeProto) Descriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TensorShapeProto) GetDim() []*TensorShapeProto_Dimension {
+	if x != nil {
+		return x.Dim
+	}
+	return nil
+}
+
+type TypeProto struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Value:
+	//
+	//	*TypeProto_TensorType
+	Value         isTypeProto_Value `protobuf_oneof:"value"`
+	Denotation    string            `protobuf:"bytes,6,opt,name=denotation,proto3" json:"denotation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TypeProto) Reset() {
+	*x = TypeProto{}
+	mi := &file_onnx_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TypeProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TypeProto) ProtoMessage() {}
+
+func (x *TypeProto) ProtoReflect() protoreflect.Message {
+	mi := &file_onnx_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TypeProto.ProtoReflect.Descriptor instead.
+func (*TypeProto) Descriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TypeProto) GetValue() isTypeProto_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *TypeProto) GetTensorType() *TypeProto_Tensor {
+	if x != nil {
+		if x, ok := x.Value.(*TypeProto_TensorType); ok {
+			return x.TensorType
+		}
+	}
+	return nil
+}
+
+func (x *TypeProto) GetDenotation() string {
+	if x != nil {
+		return x.Denotation
+	}
+	return ""
+}
+
+type isTypeProto_Value interface {
+	isTypeProto_Value()
+}
+
+type TypeProto_TensorType struct {
+	TensorType *TypeProto_Tensor `protobuf:"bytes,1,opt,name=tensor_type,json=tensorType,proto3,oneof"`
+}
+
+func (*TypeProto_TensorType) isTypeProto_Value() {}
+
+type OperatorSetIdProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	Version       int64                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OperatorSetIdProto) Reset() {
+	*x = OperatorSetIdProto{}
+	mi := &file_onnx_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OperatorSetIdProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OperatorSetIdProto) ProtoMessage() {}
+
+func (x *OperatorSetIdProto) ProtoReflect() protoreflect.Message {
+	mi := &file_onnx_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OperatorSetIdProto.ProtoReflect.Descriptor instead.
+func (*OperatorSetIdProto) Descriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *OperatorSetIdProto) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *OperatorSetIdProto) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type TensorShapeProto_Dimension struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Value:
+	//
+	//	*TensorShapeProto_Dimension_DimValue
+	//	*TensorShapeProto_Dimension_DimParam
+	Value         isTensorShapeProto_Dimension_Value `protobuf_oneof:"value"`
+	Denotation    string                             `protobuf:"bytes,3,opt,name=denotation,proto3" json:"denotation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TensorShapeProto_Dimension) Reset() {
+	*x = TensorShapeProto_Dimension{}
+	mi := &file_onnx_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TensorShapeProto_Dimension) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TensorShapeProto_Dimension) ProtoMessage() {}
+
+func (x *TensorShapeProto_Dimension) ProtoReflect() protoreflect.Message {
+	mi := &file_onnx_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TensorShapeProto_Dimension.ProtoReflect.Descriptor instead.
+func (*TensorShapeProto_Dimension) Descriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{7, 0}
+}
+
+func (x *TensorShapeProto_Dimension) GetValue() isTensorShapeProto_Dimension_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *TensorShapeProto_Dimension) GetDimValue() int64 {
+	if x != nil {
+		if x, ok := x.Value.(*TensorShapeProto_Dimension_DimValue); ok {
+			return x.DimValue
+		}
+	}
+	return 0
+}
+
+func (x *TensorShapeProto_Dimension) GetDimParam() string {
+	if x != nil {
+		if x, ok := x.Value.(*TensorShapeProto_Dimension_DimParam); ok {
+			return x.DimParam
+		}
+	}
+	return ""
+}
+
+func (x *TensorShapeProto_Dimension) GetDenotation() string {
+	if x != nil {
+		return x.Denotation
+	}
+	return ""
+}
+
+type isTensorShapeProto_Dimension_Value interface {
+	isTensorShapeProto_Dimension_Value()
+}
+
+type TensorShapeProto_Dimension_DimValue struct {
+	DimValue int64 `protobuf:"varint,1,opt,name=dim_value,json=dimValue,proto3,oneof"`
+}
+
+type TensorShapeProto_Dimension_DimParam struct {
+	DimParam string `protobuf:"bytes,2,opt,name=dim_param,json=dimParam,proto3,oneof"`
+}
+
+func (*TensorShapeProto_Dimension_DimValue) isTensorShapeProto_Dimension_Value() {}
+
+func (*TensorShapeProto_Dimension_DimParam) isTensorShapeProto_Dimension_Value() {}
+
+type TypeProto_Tensor struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ElemType      int32                  `protobuf:"varint,1,opt,name=elem_type,json=elemType,proto3" json:"elem_type,omitempty"`
+	Shape         *TensorShapeProto      `protobuf:"bytes,2,opt,name=shape,proto3" json:"shape,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TypeProto_Tensor) Reset() {
+	*x = TypeProto_Tensor{}
+	mi := &file_onnx_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TypeProto_Tensor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TypeProto_Tensor) ProtoMessage() {}
+
+func (x *TypeProto_Tensor) ProtoReflect() protoreflect.Message {
+	mi := &file_onnx_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TypeProto_Tensor.ProtoReflect.Descriptor instead.
+func (*TypeProto_Tensor) Descriptor() ([]byte, []int) {
+	return file_onnx_proto_rawDescGZIP(), []int{8, 0}
+}
+
+func (x *TypeProto_Tensor) GetElemType() int32 {
+	if x != nil {
+		return x.ElemType
+	}
+	return 0
+}
+
+func (x *TypeProto_Tensor) GetShape() *TensorShapeProto {
+	if x != nil {
+		return x.Shape
+	}
+	return nil
+}
+
+var File_onnx_proto protoreflect.FileDescriptor
+
+const file_onnx_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"onnx.proto\x12\x12gofeedforward.onnx\"\x81\x05\n" +
+	"\x0eAttributeProto\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\"\n" +
+	"\rref_attr_name\x18\x15 \x01(\tR\vrefAttrName\x12\x1d\n" +
+	"\n" +
+	"doc_string\x18\r \x01(\tR\tdocString\x12D\n" +
+	"\x04type\x18\x14 \x01(\x0e20.gofeedforward.onnx.AttributeProto.AttributeTypeR\x04type\x12\f\n" +
+	"\x01f\x18\x02 \x01(\x02R\x01f\x12\f\n" +
+	"\x01i\x18\x03 \x01(\x03R\x01i\x12\f\n" +
+	"\x01s\x18\x04 \x01(\fR\x01s\x12-\n" +
+	"\x01t\x18\x05 \x01(\v2\x1f.gofeedforward.onnx.TensorProtoR\x01t\x12,\n" +
+	"\x01g\x18\x06 \x01(\v2\x1e.gofeedforward.onnx.GraphProtoR\x01g\x12\x16\n" +
+	"\x06floats\x18\a \x03(\x02R\x06floats\x12\x12\n" +
+	"\x04ints\x18\b \x03(\x03R\x04ints\x12\x18\n" +
+	"\astrings\x18\t \x03(\fR\astrings\x129\n" +
+	"\atensors\x18\n" +
+	" \x03(\v2\x1f.gofeedforward.onnx.TensorProtoR\atensors\x126\n" +
+	"\x06graphs\x18\v \x03(\v2\x1e.gofeedforward.onnx.GraphProtoR\x06graphs\"\x91\x01\n" +
+	"\rAttributeType\x12\r\n" +
+	"\tUNDEFINED\x10\x00\x12\t\n" +
+	"\x05FLOAT\x10\x01\x12\a\n" +
+	"\x03INT\x10\x02\x12\n" +
+	"\n" +
+	"\x06STRING\x10\x03\x12\n" +
+	"\n" +
+	"\x06TENSOR\x10\x04\x12\t\n" +
+	"\x05GRAPH\x10\x05\x12\n" +
+	"\n" +
+	"\x06FLOATS\x10\x06\x12\b\n" +
+	"\x04INTS\x10\a\x12\v\n" +
+	"\aSTRINGS\x10\b\x12\v\n" +
+	"\aTENSORS\x10\t\x12\n" +
+	"\n" +
+	"\x06GRAPHS\x10\n" +
+	"\"v\n" +
+	"\x0eValueInfoProto\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x121\n" +
+	"\x04type\x18\x02 \x01(\v2\x1d.gofeedforward.onnx.TypeProtoR\x04type\x12\x1d\n" +
+	"\n" +
+	"doc_string\x18\x03 \x01(\tR\tdocString\"\xdf\x01\n" +
+	"\tNodeProto\x12\x14\n" +
+	"\x05input\x18\x01 \x03(\tR\x05input\x12\x16\n" +
+	"\x06output\x18\x02 \x03(\tR\x06output\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x17\n" +
+	"\aop_type\x18\x04 \x01(\tR\x06opType\x12\x16\n" +
+	"\x06domain\x18\a \x01(\tR\x06domain\x12@\n" +
+	"\tattribute\x18\x05 \x03(\v2\".gofeedforward.onnx.AttributeProtoR\tattribute\x12\x1d\n" +
+	"\n" +
+	"doc_string\x18\x06 \x01(\tR\tdocString\"\xab\x03\n" +
+	"\n" +
+	"ModelProto\x12\x1d\n" +
+	"\n" +
+	"ir_version\x18\x01 \x01(\x03R\tirVersion\x12I\n" +
+	"\fopset_import\x18\b \x03(\v2&.gofeedforward.onnx.OperatorSetIdProtoR\vopsetImport\x12#\n" +
+	"\rproducer_name\x18\x02 \x01(\tR\fproducerName\x12)\n" +
+	"\x10producer_version\x18\x03 \x01(\tR\x0fproducerVersion\x12\x16\n" +
+	"\x06domain\x18\x04 \x01(\tR\x06domain\x12#\n" +
+	"\rmodel_version\x18\x05 \x01(\x03R\fmodelVersion\x12\x1d\n" +
+	"\n" +
+	"doc_string\x18\x06 \x01(\tR\tdocString\x124\n" +
+	"\x05graph\x18\a \x01(\v2\x1e.gofeedforward.onnx.GraphProtoR\x05graph\x12Q\n" +
+	"\x0emetadata_props\x18\x0e \x03(\v2*.gofeedforward.onnx.StringStringEntryProtoR\rmetadataProps\"@\n" +
+	"\x16StringStringEntryProto\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"\xee\x02\n" +
+	"\n" +
+	"GraphProto\x121\n" +
+	"\x04node\x18\x01 \x03(\v2\x1d.gofeedforward.onnx.NodeProtoR\x04node\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12A\n" +
+	"\vinitializer\x18\x05 \x03(\v2\x1f.gofeedforward.onnx.TensorProtoR\vinitializer\x12\x1d\n" +
+	"\n" +
+	"doc_string\x18\n" +
+	" \x01(\tR\tdocString\x128\n" +
+	"\x05input\x18\v \x03(\v2\".gofeedforward.onnx.ValueInfoProtoR\x05input\x12:\n" +
+	"\x06output\x18\f \x03(\v2\".gofeedforward.onnx.ValueInfoProtoR\x06output\x12A\n" +
+	"\n" +
+	"value_info\x18\r \x03(\v2\".gofeedforward.onnx.ValueInfoProtoR\tvalueInfo\"\xa9\x04\n" +
+	"\vTensorProto\x12\x12\n" +
+	"\x04dims\x18\x01 \x03(\x03R\x04dims\x12\x1b\n" +
+	"\tdata_type\x18\x02 \x01(\x05R\bdataType\x12\x1d\n" +
+	"\n" +
+	"float_data\x18\x04 \x03(\x02R\tfloatData\x12\x1d\n" +
+	"\n" +
+	"int32_data\x18\x05 \x03(\x05R\tint32Data\x12\x1f\n" +
+	"\vstring_data\x18\x06 \x03(\fR\n" +
+	"stringData\x12\x1d\n" +
+	"\n" +
+	"int64_data\x18\a \x03(\x03R\tint64Data\x12\x12\n" +
+	"\x04name\x18\b \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"doc_string\x18\f \x01(\tR\tdocString\x12\x19\n" +
+	"\braw_data\x18\t \x01(\fR\arawData\x12\x1f\n" +
+	"\vdouble_data\x18\n" +
+	" \x03(\x01R\n" +
+	"doubleData\x12\x1f\n" +
+	"\vuint64_data\x18\v \x03(\x04R\n" +
+	"uint64Data\"\xda\x01\n" +
+	"\bDataType\x12\r\n" +
+	"\tUNDEFINED\x10\x00\x12\t\n" +
+	"\x05FLOAT\x10\x01\x12\t\n" +
+	"\x05UINT8\x10\x02\x12\b\n" +
+	"\x04INT8\x10\x03\x12\n" +
+	"\n" +
+	"\x06UINT16\x10\x04\x12\t\n" +
+	"\x05INT16\x10\x05\x12\t\n" +
+	"\x05INT32\x10\x06\x12\t\n" +
+	"\x05INT64\x10\a\x12\n" +
+	"\n" +
+	"\x06STRING\x10\b\x12\b\n" +
+	"\x04BOOL\x10\t\x12\v\n" +
+	"\aFLOAT16\x10\n" +
+	"\x12\n" +
+	"\n" +
+	"\x06DOUBLE\x10\v\x12\n" +
+	"\n" +
+	"\x06UINT32\x10\f\x12\n" +
+	"\n" +
+	"\x06UINT64\x10\r\x12\r\n" +
+	"\tCOMPLEX64\x10\x0e\x12\x0e\n" +
+	"\n" +
+	"COMPLEX128\x10\x0f\x12\f\n" +
+	"\bBFLOAT16\x10\x10\"\xc8\x01\n" +
+	"\x10TensorShapeProto\x12@\n" +
+	"\x03dim\x18\x01 \x03(\v2..gofeedforward.onnx.TensorShapeProto.DimensionR\x03dim\x1ar\n" +
+	"\tDimension\x12\x1d\n" +
+	"\tdim_value\x18\x01 \x01(\x03H\x00R\bdimValue\x12\x1d\n" +
+	"\tdim_param\x18\x02 \x01(\tH\x00R\bdimParam\x12\x1e\n" +
+	"\n" +
+	"denotation\x18\x03 \x01(\tR\n" +
+	"denotationB\a\n" +
+	"\x05value\"\xe0\x01\n" +
+	"\tTypeProto\x12G\n" +
+	"\vtensor_type\x18\x01 \x01(\v2$.gofeedforward.onnx.TypeProto.TensorH\x00R\n" +
+	"tensorType\x12\x1e\n" +
+	"\n" +
+	"denotation\x18\x06 \x01(\tR\n" +
+	"denotation\x1aa\n" +
+	"\x06Tensor\x12\x1b\n" +
+	"\telem_type\x18\x01 \x01(\x05R\belemType\x12:\n" +
+	"\x05shape\x18\x02 \x01(\v2$.gofeedforward.onnx.TensorShapeProtoR\x05shapeB\a\n" +
+	"\x05value\"F\n" +
+	"\x12OperatorSetIdProto\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversionB'Z%github.com/DarcInc/gofeedforward/onnxb\x06proto3"
+
+var (
+	file_onnx_proto_rawDescOnce sync.Once
+	file_onnx_proto_rawDescData []byte
+)
+
+func file_onnx_proto_rawDescGZIP() []byte {
+	file_onnx_proto_rawDescOnce.Do(func() {
+		file_onnx_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_onnx_proto_rawDesc), len(file_onnx_proto_rawDesc)))
+	})
+	return file_onnx_proto_rawDescData
+}
+
+var file_onnx_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_onnx_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_onnx_proto_goTypes = []any{
+	(AttributeProto_AttributeType)(0),  // 0: gofeedforward.onnx.AttributeProto.AttributeType
+	(TensorProto_DataType)(0),          // 1: gofeedforward.onnx.TensorProto.DataType
+	(*AttributeProto)(nil),             // 2: gofeedforward.onnx.AttributeProto
+	(*ValueInfoProto)(nil),             // 3: gofeedforward.onnx.ValueInfoProto
+	(*NodeProto)(nil),                  // 4: gofeedforward.onnx.NodeProto
+	(*ModelProto)(nil),                 // 5: gofeedforward.onnx.ModelProto
+	(*StringStringEntryProto)(nil),     // 6: gofeedforward.onnx.StringStringEntryProto
+	(*GraphProto)(nil),                 // 7: gofeedforward.onnx.GraphProto
+	(*TensorProto)(nil),                // 8: gofeedforward.onnx.TensorProto
+	(*TensorShapeProto)(nil),           // 9: gofeedforward.onnx.TensorShapeProto
+	(*TypeProto)(nil),                  // 10: gofeedforward.onnx.TypeProto
+	(*OperatorSetIdProto)(nil),         // 11: gofeedforward.onnx.OperatorSetIdProto
+	(*TensorShapeProto_Dimension)(nil), // 12: gofeedforward.onnx.TensorShapeProto.Dimension
+	(*TypeProto_Tensor)(nil),           // 13: gofeedforward.onnx.TypeProto.Tensor
+}
+var file_onnx_proto_depIdxs = []int32{
+	0,  // 0: gofeedforward.onnx.AttributeProto.type:type_name -> gofeedforward.onnx.AttributeProto.AttributeType
+	8,  // 1: gofeedforward.onnx.AttributeProto.t:type_name -> gofeedforward.onnx.TensorProto
+	7,  // 2: gofeedforward.onnx.AttributeProto.g:type_name -> gofeedforward.onnx.GraphProto
+	8,  // 3: gofeedforward.onnx.AttributeProto.tensors:type_name -> gofeedforward.onnx.TensorProto
+	7,  // 4: gofeedforward.onnx.AttributeProto.graphs:type_name -> gofeedforward.onnx.GraphProto
+	10, // 5: gofeedforward.onnx.ValueInfoProto.type:type_name -> gofeedforward.onnx.TypeProto
+	2,  // 6: gofeedforward.onnx.NodeProto.attribute:type_name -> gofeedforward.onnx.AttributeProto
+	11, // 7: gofeedforward.onnx.ModelProto.opset_import:type_name -> gofeedforward.onnx.OperatorSetIdProto
+	7,  // 8: gofeedforward.onnx.ModelProto.graph:type_name -> gofeedforward.onnx.GraphProto
+	6,  // 9: gofeedforward.onnx.ModelProto.metadata_props:type_name -> gofeedforward.onnx.StringStringEntryProto
+	4,  // 10: gofeedforward.onnx.GraphProto.node:type_name -> gofeedforward.onnx.NodeProto
+	8,  // 11: gofeedforward.onnx.GraphProto.initializer:type_name -> gofeedforward.onnx.TensorProto
+	3,  // 12: gofeedforward.onnx.GraphProto.input:type_name -> gofeedforward.onnx.ValueInfoProto
+	3,  // 13: gofeedforward.onnx.GraphProto.output:type_name -> gofeedforward.onnx.ValueInfoProto
+	3,  // 14: gofeedforward.onnx.GraphProto.value_info:type_name -> gofeedforward.onnx.ValueInfoProto
+	12, // 15: gofeedforward.onnx.TensorShapeProto.dim:type_name -> gofeedforward.onnx.TensorShapeProto.Dimension
+	13, // 16: gofeedforward.onnx.TypeProto.tensor_type:type_name -> gofeedforward.onnx.TypeProto.Tensor
+	9,  // 17: gofeedforward.onnx.TypeProto.Tensor.shape:type_name -> gofeedforward.onnx.TensorShapeProto
+	18, // [18:18] is the sub-list for method output_type
+	18, // [18:18] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_onnx_proto_init() }
+func file_onnx_proto_init() {
+	if File_onnx_proto != nil {
+		return
+	}
+	file_onnx_proto_msgTypes[8].OneofWrappers = []any{
+		(*TypeProto_TensorType)(nil),
+	}
+	file_onnx_proto_msgTypes[10].OneofWrappers = []any{
+		(*TensorShapeProto_Dimension_DimValue)(nil),
+		(*TensorShapeProto_Dimension_DimParam)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_onnx_proto_rawDesc), len(file_onnx_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_onnx_proto_goTypes,
+		DependencyIndexes: file_onnx_proto_depIdxs,
+		EnumInfos:         file_onnx_proto_enumTypes,
+		MessageInfos:      file_onnx_proto_msgTypes,
+	}.Build()
+	File_onnx_proto = out.File
+	file_onnx_proto_goTypes = nil
+	file_onnx_proto_depIdxs = nil
+}
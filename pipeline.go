@@ -0,0 +1,334 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Field describes how one field of a raw record becomes network inputs.  A
+// field with no encoder is parsed as a number and produces a single input; any
+// of the DefaultMissingValues produces NaN for an Imputer to replace.
+// Otherwise the field is a category and is encoded with whichever encoder is
+// set.
+type Field struct {
+	Name    string
+	OneHot  *OneHotEncoder  `json:",omitempty"`
+	Ordinal *OrdinalEncoder `json:",omitempty"`
+	Hash    *HashEncoder    `json:",omitempty"`
+}
+
+// ClassifierSpec describes a BasicClassifier in a way that can be saved.  Type
+// is "best" for MakeBestOfClassifier, "threshold" for MakeThresholdClassifier
+// or "ordinal" for an OrdinalEncoder's classifier.
+type ClassifierSpec struct {
+	Type      string
+	Classes   []string
+	Threshold float64 `json:",omitempty"`
+}
+
+// Pipeline bundles everything needed to go from a raw record to a prediction:
+// the fields that turn a record into inputs, the imputers and then the scalers
+// applied to the inputs in order, the network, an optional scaler that is reversed on the outputs and
+// an optional classifier.  The whole pipeline is saved as a single artifact so
+// a deployed network is always used with the preprocessing it was trained
+// with.  Like a Network, a Pipeline is not safe for concurrent use.
+type Pipeline struct {
+	Fields       []Field
+	Imputers     []Imputer
+	Scalers      []Scaler
+	Network      Network
+	OutputScaler *Scaler         `json:",omitempty"`
+	Classifier   *ClassifierSpec `json:",omitempty"`
+}
+
+// Size returns the number of inputs the field produces.
+func (f Field) Size() int {
+	if encoder := f.encoder(); encoder != nil {
+		return encoder.Size()
+	}
+	return 1
+}
+
+func (f Field) encoder() Encoder {
+	switch {
+	case f.OneHot != nil:
+		return f.OneHot
+	case f.Ordinal != nil:
+		return f.Ordinal
+	case f.Hash != nil:
+		return f.Hash
+	}
+	return nil
+}
+
+// Encode turns the raw value of the field into inputs.
+func (f Field) Encode(value string) ([]float64, error) {
+	if encoder := f.encoder(); encoder != nil {
+		return encoder.Encode(value)
+	}
+
+	value = strings.TrimSpace(value)
+	for _, missing := range DefaultMissingValues {
+		if strings.EqualFold(value, missing) {
+			return []float64{math.NaN()}, nil
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a number", value)
+	}
+	return []float64{number}, nil
+}
+
+// Classifier creates the BasicClassifier the spec describes.
+func (c ClassifierSpec) Classifier() (BasicClassifier, error) {
+	switch c.Type {
+	case "best":
+		return MakeBestOfClassifier(c.Classes), nil
+	case "threshold":
+		return MakeThresholdClassifier(c.Classes, c.Threshold), nil
+	case "ordinal":
+		return OrdinalEncoder{Categories: c.Classes}.Classifier(), nil
+	}
+	return nil, fmt.Errorf("Unknown classifier type %q", c.Type)
+}
+
+// Validate checks that the spec describes a known classifier and that its
+// classes fit a network with the given number of outputs: one output for each
+// class, or a single output for an ordinal classifier.
+func (c ClassifierSpec) Validate(outputs int) error {
+	if _, err := c.Classifier(); err != nil {
+		return err
+	}
+
+	if c.Type == "ordinal" {
+		if outputs != 1 || len(c.Classes) == 0 {
+			return fmt.Errorf("Ordinal classifier with %d classes needs 1 output but the network has %d", len(c.Classes), outputs)
+		}
+		return nil
+	}
+
+	if len(c.Classes) != outputs {
+		return fmt.Errorf("Classifier has %d classes but the network has %d outputs", len(c.Classes), outputs)
+	}
+	return nil
+}
+
+// Inputs turns a raw record, with one value per field, into scaled network
+// inputs.  It is an error for a value to still be missing once the imputers
+// have been applied.
+func (p Pipeline) Inputs(record []string) ([]float64, error) {
+	if len(record) != len(p.Fields) {
+		return nil, fmt.Errorf("Expected %d fields but got %d", len(p.Fields), len(record))
+	}
+
+	inputs := []float64{}
+	for idx, field := range p.Fields {
+		values, err := field.Encode(record[idx])
+		if err != nil {
+			return nil, fmt.Errorf("Field %d (%s): %v", idx, field.Name, err)
+		}
+		inputs = append(inputs, values...)
+	}
+
+	var err error
+	for _, imputer := range p.Imputers {
+		if inputs, err = imputer.Transform(inputs); err != nil {
+			return nil, err
+		}
+	}
+
+	for idx, value := range inputs {
+		if isInvalid(value) {
+			return nil, fmt.Errorf("Input %d is missing or invalid: %v", idx, value)
+		}
+	}
+
+	for _, scaler := range p.Scalers {
+		if inputs, err = scaler.Transform(inputs); err != nil {
+			return nil, err
+		}
+	}
+	return inputs, nil
+}
+
+// Predict runs a raw record through the pipeline and returns the network
+// outputs, in their original units if there is an output scaler.
+func (p *Pipeline) Predict(record []string) ([]float64, error) {
+	inputs, err := p.Inputs(record)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := p.Network.Process(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.OutputScaler != nil {
+		return p.OutputScaler.Inverse(outputs)
+	}
+	return outputs, nil
+}
+
+// Classify runs a raw record through the pipeline and classifies the network
+// outputs.  The pipeline must have a classifier.  The classifier is applied to
+// the raw network outputs, before any output scaler.
+func (p *Pipeline) Classify(record []string) ([]string, error) {
+	if p.Classifier == nil {
+		return nil, fmt.Errorf("Pipeline has no classifier")
+	}
+
+	classifier, err := p.Classifier.Classifier()
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, err := p.Inputs(record)
+	if err != nil {
+		return nil, err
+	}
+	return p.Network.Classify(inputs, classifier)
+}
+
+// InputNames names each network input the pipeline produces.  A number or
+// ordinal field produces its own name, a one hot field produces name=category
+// for each category and a hash field produces name#bucket for each bucket.
+// The missing value indicators added by imputers are named after the input
+// with a _missing suffix.
+func (p Pipeline) InputNames() []string {
+	names := []string{}
+	for _, field := range p.Fields {
+		switch {
+		case field.OneHot != nil:
+			for _, category := range field.OneHot.Categories {
+				names = append(names, field.Name+"="+category)
+			}
+		case field.Hash != nil:
+			for bucket := 0; bucket < field.Hash.Buckets; bucket++ {
+				names = append(names, fmt.Sprintf("%s#%d", field.Name, bucket))
+			}
+		default:
+			names = append(names, field.Name)
+		}
+	}
+
+	for _, imputer := range p.Imputers {
+		if !imputer.Indicators {
+			continue
+		}
+		for _, col := range imputer.Columns {
+			name := fmt.Sprintf("input%d", col)
+			if col >= 0 && col < len(names) {
+				name = names[col]
+			}
+			names = append(names, name+"_missing")
+		}
+	}
+	return names
+}
+
+// Validate checks that the network is valid, that the fields, once encoded
+// and imputed, produce as many inputs as the network expects, that the
+// imputers and scalers fit the values they are applied to, that any
+// classifier has a class for each network output and, if the network's
+// metadata names its inputs, that they are the inputs the fields produce.
+func (p Pipeline) Validate() error {
+	if err := p.Network.Validate(); err != nil {
+		return err
+	}
+
+	size := 0
+	for _, field := range p.Fields {
+		size += field.Size()
+	}
+
+	for _, imputer := range p.Imputers {
+		if err := imputer.Validate(size); err != nil {
+			return err
+		}
+		size += imputer.Size()
+	}
+
+	if size != p.Network.InputSize() {
+		return fmt.Errorf("Fields produce %d inputs but the network expects %d", size, p.Network.InputSize())
+	}
+
+	for _, scaler := range p.Scalers {
+		if err := scaler.Validate(size); err != nil {
+			return err
+		}
+	}
+
+	if p.OutputScaler != nil {
+		if err := p.OutputScaler.Validate(p.Network.OutputSize()); err != nil {
+			return fmt.Errorf("Output scaler: %v", err)
+		}
+	}
+
+	if len(p.Fields) > 0 && p.Network.Metadata != nil {
+		if err := p.Network.Metadata.CheckInputs(p.InputNames()); err != nil {
+			return err
+		}
+	}
+
+	if p.Classifier != nil {
+		if err := p.Classifier.Validate(p.Network.OutputSize()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save writes the pipeline as JSON.
+func (p Pipeline) Save(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
+
+// LoadPipeline reads a pipeline written by Save and validates it.
+func LoadPipeline(r io.Reader) (Pipeline, error) {
+	var result Pipeline
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return Pipeline{}, err
+	}
+
+	if err := result.Validate(); err != nil {
+		return Pipeline{}, err
+	}
+	return result, nil
+}
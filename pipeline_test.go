@@ -0,0 +1,211 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"bytes"
+	"testing"
+)
+
+func makeTestPipeline() Pipeline {
+	colors := FitOneHotEncoder([]string{"red", "blue"}, 1.0, 0.0)
+	net := MakeNetwork(3, 4, 2)
+	net.Randomize()
+
+	scaler, _ := FitMinMaxScaler([][]float64{{0.0, 0.0, 10.0}, {1.0, 1.0, 20.0}}, 0.0, 1.0, 2)
+	return Pipeline{
+		Fields:     []Field{{Name: "color", OneHot: &colors}, {Name: "size"}},
+		Scalers:    []Scaler{scaler},
+		Network:    net,
+		Classifier: &ClassifierSpec{Type: "best", Classes: []string{"small", "large"}},
+	}
+}
+
+func TestPipeline_Inputs(t *testing.T) {
+	p := makeTestPipeline()
+
+	inputs, err := p.Inputs([]string{"red", "15"})
+	if err != nil {
+		t.Errorf("Failed to make inputs: %v", err)
+	}
+
+	if len(inputs) != 3 || inputs[0] != 0.0 || inputs[1] != 1.0 || outOfBoundsCheck(0.5, inputs[2], 0.0001) {
+		t.Errorf("Expected [0 1 0.5] but got %v", inputs)
+	}
+
+	if _, err := p.Inputs([]string{"green", "15"}); err == nil {
+		t.Error("Expected an error for an unknown category")
+	}
+
+	if _, err := p.Inputs([]string{"red", "big"}); err == nil {
+		t.Error("Expected an error for a field that is not a number")
+	}
+}
+
+func TestPipeline_SaveLoad(t *testing.T) {
+	p := makeTestPipeline()
+	outputs, _ := p.Predict([]string{"blue", "12"})
+	classes, _ := p.Classify([]string{"blue", "12"})
+
+	var buf bytes.Buffer
+	if err := p.Save(&buf); err != nil {
+		t.Errorf("Failed to save pipeline: %v", err)
+	}
+
+	loaded, err := LoadPipeline(&buf)
+	if err != nil {
+		t.Errorf("Failed to load pipeline: %v", err)
+	}
+
+	loadedOutputs, _ := loaded.Predict([]string{"blue", "12"})
+	for idx := range outputs {
+		if outOfBoundsCheck(outputs[idx], loadedOutputs[idx], 0.0000001) {
+			t.Errorf("Expected %v but got %v", outputs, loadedOutputs)
+		}
+	}
+
+	loadedClasses, _ := loaded.Classify([]string{"blue", "12"})
+	if len(loadedClasses) != 1 || loadedClasses[0] != classes[0] {
+		t.Errorf("Expected %v but got %v", classes, loadedClasses)
+	}
+}
+
+func TestPipeline_OutputScaler(t *testing.T) {
+	net := MakeNetwork(1, 1)
+	scaler, _ := FitMinMaxScaler([][]float64{{100.0}, {200.0}}, 0.0, 1.0)
+	p := Pipeline{Fields: []Field{{Name: "x"}}, Network: net, OutputScaler: &scaler}
+
+	outputs, _ := p.Predict([]string{"1"})
+	if outOfBoundsCheck(150.0, outputs[0], 0.0001) {
+		t.Errorf("Expected the output to be unscaled to 150.0 but got %0.4f", outputs[0])
+	}
+}
+
+func TestLoadPipelineInvalid(t *testing.T) {
+	p := makeTestPipeline()
+	p.Fields = p.Fields[:1]
+
+	var buf bytes.Buffer
+	p.Save(&buf)
+	if _, err := LoadPipeline(&buf); err == nil {
+		t.Error("Expected an error when the fields do not match the network")
+	}
+}
+
+func TestPipeline_InputNames(t *testing.T) {
+	p := makeTestPipeline()
+	p.Imputers = []Imputer{{Strategy: MeanImputation, Columns: []int{2}, Values: []float64{15.0}, Indicators: true}}
+	p.Network = MakeNetwork(4, 2)
+
+	names := p.InputNames()
+	expected := []string{"color=blue", "color=red", "size", "size_missing"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v but got %v", expected, names)
+	}
+	for idx := range expected {
+		if names[idx] != expected[idx] {
+			t.Errorf("Expected %v but got %v", expected, names)
+		}
+	}
+
+	metadata := MakeMetadata()
+	metadata.Inputs = []string{"color=blue", "color=red", "weight", "size_missing"}
+	p.Network.Metadata = &metadata
+	if err := p.Validate(); err == nil {
+		t.Error("Expected an error when the metadata names other inputs")
+	}
+
+	metadata.Inputs = expected
+	if err := p.Validate(); err != nil {
+		t.Errorf("Expected the metadata to match the fields but got %v", err)
+	}
+}
+
+func TestLoadPipelineInvalidScaler(t *testing.T) {
+	p := makeTestPipeline()
+	p.Scalers[0].Center = p.Scalers[0].Center[:0]
+
+	var buf bytes.Buffer
+	p.Save(&buf)
+	if _, err := LoadPipeline(&buf); err == nil {
+		t.Error("Expected an error for a scaler without a center for its column")
+	}
+
+	p = makeTestPipeline()
+	p.Scalers[0].Columns = []int{3}
+	buf.Reset()
+	p.Save(&buf)
+	if _, err := LoadPipeline(&buf); err == nil {
+		t.Error("Expected an error for a scaler column beyond the inputs")
+	}
+
+	p = makeTestPipeline()
+	output, _ := FitMinMaxScaler([][]float64{{0.0, 0.0, 0.0}, {1.0, 1.0, 1.0}}, 0.0, 1.0)
+	p.OutputScaler = &output
+	buf.Reset()
+	p.Save(&buf)
+	if _, err := LoadPipeline(&buf); err == nil {
+		t.Error("Expected an error for an output scaler that does not cover the outputs")
+	}
+
+	p = makeTestPipeline()
+	p.Scalers[0].Method = "percentile"
+	buf.Reset()
+	p.Save(&buf)
+	if _, err := LoadPipeline(&buf); err == nil {
+		t.Error("Expected an error for an unknown scaling method")
+	}
+}
+
+func TestLoadPipelineInvalidClassifier(t *testing.T) {
+	cases := map[string]ClassifierSpec{
+		"too many classes": {Type: "best", Classes: []string{"small", "medium", "large"}},
+		"too few classes":  {Type: "threshold", Classes: []string{"small"}, Threshold: 0.5},
+		"ordinal":          {Type: "ordinal", Classes: []string{"small", "large"}},
+		"unknown type":     {Type: "nearest", Classes: []string{"small", "large"}},
+	}
+
+	for name, spec := range cases {
+		p := makeTestPipeline()
+		p.Classifier = &spec
+
+		var buf bytes.Buffer
+		p.Save(&buf)
+		if _, err := LoadPipeline(&buf); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	p := makeTestPipeline()
+	p.Network = MakeNetwork(3, 1)
+	p.Classifier = &ClassifierSpec{Type: "ordinal", Classes: []string{"small", "medium", "large"}}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Expected an ordinal classifier with one output to be valid but got %v", err)
+	}
+}
@@ -0,0 +1,532 @@
+//
+//BSD 2-Clause License
+//
+//Copyright (c) 2016, Darc Inc
+//All rights reserved.
+//
+//Redistribution and use in source and binary forms, with or without
+//modification, are permitted provided that the following conditions are met:
+//
+// Redistributions of source code must retain the above copyright notice, this
+//list of conditions and the following disclaimer.
+//
+// Redistributions in binary form must reproduce the above copyright notice,
+//this list of conditions and the following disclaimer in the documentation
+//and/or other materials provided with the distribution.
+//
+//THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+//AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+//IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+//DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+//FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+//DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+//SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+//CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+//OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+//OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: predictor.proto
+
+package predictor
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Example is one set of inputs.  Inputs are presented to the network as they
+// are, while a record holds one raw value per pipeline field and goes through
+// the fields, imputers and scalers first.  Set one or the other.
+type Example struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Inputs        []float64              `protobuf:"fixed64,1,rep,packed,name=inputs,proto3" json:"inputs,omitempty"`
+	Record        []string               `protobuf:"bytes,2,rep,name=record,proto3" json:"record,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Example) Reset() {
+	*x = Example{}
+	mi := &file_predictor_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Example) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Example) ProtoMessage() {}
+
+func (x *Example) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Example.ProtoReflect.Descriptor instead.
+func (*Example) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Example) GetInputs() []float64 {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+func (x *Example) GetRecord() []string {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+type PredictRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Examples      []*Example             `protobuf:"bytes,1,rep,name=examples,proto3" json:"examples,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PredictRequest) Reset() {
+	*x = PredictRequest{}
+	mi := &file_predictor_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PredictRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictRequest) ProtoMessage() {}
+
+func (x *PredictRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictRequest.ProtoReflect.Descriptor instead.
+func (*PredictRequest) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PredictRequest) GetExamples() []*Example {
+	if x != nil {
+		return x.Examples
+	}
+	return nil
+}
+
+type Prediction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Outputs       []float64              `protobuf:"fixed64,1,rep,packed,name=outputs,proto3" json:"outputs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Prediction) Reset() {
+	*x = Prediction{}
+	mi := &file_predictor_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Prediction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Prediction) ProtoMessage() {}
+
+func (x *Prediction) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Prediction.ProtoReflect.Descriptor instead.
+func (*Prediction) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Prediction) GetOutputs() []float64 {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+type PredictResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Predictions   []*Prediction          `protobuf:"bytes,1,rep,name=predictions,proto3" json:"predictions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PredictResponse) Reset() {
+	*x = PredictResponse{}
+	mi := &file_predictor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PredictResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictResponse) ProtoMessage() {}
+
+func (x *PredictResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictResponse.ProtoReflect.Descriptor instead.
+func (*PredictResponse) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PredictResponse) GetPredictions() []*Prediction {
+	if x != nil {
+		return x.Predictions
+	}
+	return nil
+}
+
+type Classification struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Classes       []string               `protobuf:"bytes,1,rep,name=classes,proto3" json:"classes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Classification) Reset() {
+	*x = Classification{}
+	mi := &file_predictor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Classification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Classification) ProtoMessage() {}
+
+func (x *Classification) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Classification.ProtoReflect.Descriptor instead.
+func (*Classification) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Classification) GetClasses() []string {
+	if x != nil {
+		return x.Classes
+	}
+	return nil
+}
+
+type ClassifyResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Classifications []*Classification      `protobuf:"bytes,1,rep,name=classifications,proto3" json:"classifications,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ClassifyResponse) Reset() {
+	*x = ClassifyResponse{}
+	mi := &file_predictor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClassifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClassifyResponse) ProtoMessage() {}
+
+func (x *ClassifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClassifyResponse.ProtoReflect.Descriptor instead.
+func (*ClassifyResponse) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ClassifyResponse) GetClassifications() []*Classification {
+	if x != nil {
+		return x.Classifications
+	}
+	return nil
+}
+
+type ModelInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModelInfoRequest) Reset() {
+	*x = ModelInfoRequest{}
+	mi := &file_predictor_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModelInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelInfoRequest) ProtoMessage() {}
+
+func (x *ModelInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelInfoRequest.ProtoReflect.Descriptor instead.
+func (*ModelInfoRequest) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{6}
+}
+
+type ModelInfoResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Layer sizes starting with the inputs, as passed to MakeNetwork.
+	Sizes []int32 `protobuf:"varint,1,rep,packed,name=sizes,proto3" json:"sizes,omitempty"`
+	// Names of the pipeline fields, empty if the model only accepts inputs.
+	Fields []string `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty"`
+	// Whether the model can classify, and the classes it knows about if the
+	// classifier was saved with the pipeline.
+	CanClassify   bool     `protobuf:"varint,3,opt,name=can_classify,json=canClassify,proto3" json:"can_classify,omitempty"`
+	Classes       []string `protobuf:"bytes,4,rep,name=classes,proto3" json:"classes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModelInfoResponse) Reset() {
+	*x = ModelInfoResponse{}
+	mi := &file_predictor_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModelInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelInfoResponse) ProtoMessage() {}
+
+func (x *ModelInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_predictor_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelInfoResponse.ProtoReflect.Descriptor instead.
+func (*ModelInfoResponse) Descriptor() ([]byte, []int) {
+	return file_predictor_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ModelInfoResponse) GetSizes() []int32 {
+	if x != nil {
+		return x.Sizes
+	}
+	return nil
+}
+
+func (x *ModelInfoResponse) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *ModelInfoResponse) GetCanClassify() bool {
+	if x != nil {
+		return x.CanClassify
+	}
+	return false
+}
+
+func (x *ModelInfoResponse) GetClasses() []string {
+	if x != nil {
+		return x.Classes
+	}
+	return nil
+}
+
+var File_predictor_proto protoreflect.FileDescriptor
+
+const file_predictor_proto_rawDesc = "" +
+	"\n" +
+	"\x0fpredictor.proto\x12\x17gofeedforward.predictor\"9\n" +
+	"\aExample\x12\x16\n" +
+	"\x06inputs\x18\x01 \x03(\x01R\x06inputs\x12\x16\n" +
+	"\x06record\x18\x02 \x03(\tR\x06record\"N\n" +
+	"\x0ePredictRequest\x12<\n" +
+	"\bexamples\x18\x01 \x03(\v2 .gofeedforward.predictor.ExampleR\bexamples\"&\n" +
+	"\n" +
+	"Prediction\x12\x18\n" +
+	"\aoutputs\x18\x01 \x03(\x01R\aoutputs\"X\n" +
+	"\x0fPredictResponse\x12E\n" +
+	"\vpredictions\x18\x01 \x03(\v2#.gofeedforward.predictor.PredictionR\vpredictions\"*\n" +
+	"\x0eClassification\x12\x18\n" +
+	"\aclasses\x18\x01 \x03(\tR\aclasses\"e\n" +
+	"\x10ClassifyResponse\x12Q\n" +
+	"\x0fclassifications\x18\x01 \x03(\v2'.gofeedforward.predictor.ClassificationR\x0fclassifications\"\x12\n" +
+	"\x10ModelInfoRequest\"~\n" +
+	"\x11ModelInfoResponse\x12\x14\n" +
+	"\x05sizes\x18\x01 \x03(\x05R\x05sizes\x12\x16\n" +
+	"\x06fields\x18\x02 \x03(\tR\x06fields\x12!\n" +
+	"\fcan_classify\x18\x03 \x01(\bR\vcanClassify\x12\x18\n" +
+	"\aclasses\x18\x04 \x03(\tR\aclasses2\xea\x03\n" +
+	"\tPredictor\x12\\\n" +
+	"\aPredict\x12'.gofeedforward.predictor.PredictRequest\x1a(.gofeedforward.predictor.PredictResponse\x12^\n" +
+	"\bClassify\x12'.gofeedforward.predictor.PredictRequest\x1a).gofeedforward.predictor.ClassifyResponse\x12b\n" +
+	"\tModelInfo\x12).gofeedforward.predictor.ModelInfoRequest\x1a*.gofeedforward.predictor.ModelInfoResponse\x12Z\n" +
+	"\rPredictStream\x12 .gofeedforward.predictor.Example\x1a#.gofeedforward.predictor.Prediction(\x010\x01\x12_\n" +
+	"\x0eClassifyStream\x12 .gofeedforward.predictor.Example\x1a'.gofeedforward.predictor.Classification(\x010\x01B,Z*github.com/DarcInc/gofeedforward/predictorb\x06proto3"
+
+var (
+	file_predictor_proto_rawDescOnce sync.Once
+	file_predictor_proto_rawDescData []byte
+)
+
+func file_predictor_proto_rawDescGZIP() []byte {
+	file_predictor_proto_rawDescOnce.Do(func() {
+		file_predictor_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_predictor_proto_rawDesc), len(file_predictor_proto_rawDesc)))
+	})
+	return file_predictor_proto_rawDescData
+}
+
+var file_predictor_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_predictor_proto_goTypes = []any{
+	(*Example)(nil),           // 0: gofeedforward.predictor.Example
+	(*PredictRequest)(nil),    // 1: gofeedforward.predictor.PredictRequest
+	(*Prediction)(nil),        // 2: gofeedforward.predictor.Prediction
+	(*PredictResponse)(nil),   // 3: gofeedforward.predictor.PredictResponse
+	(*Classification)(nil),    // 4: gofeedforward.predictor.Classification
+	(*ClassifyResponse)(nil),  // 5: gofeedforward.predictor.ClassifyResponse
+	(*ModelInfoRequest)(nil),  // 6: gofeedforward.predictor.ModelInfoRequest
+	(*ModelInfoResponse)(nil), // 7: gofeedforward.predictor.ModelInfoResponse
+}
+var file_predictor_proto_depIdxs = []int32{
+	0, // 0: gofeedforward.predictor.PredictRequest.examples:type_name -> gofeedforward.predictor.Example
+	2, // 1: gofeedforward.predictor.PredictResponse.predictions:type_name -> gofeedforward.predictor.Prediction
+	4, // 2: gofeedforward.predictor.ClassifyResponse.classifications:type_name -> gofeedforward.predictor.Classification
+	1, // 3: gofeedforward.predictor.Predictor.Predict:input_type -> gofeedforward.predictor.PredictRequest
+	1, // 4: gofeedforward.predictor.Predictor.Classify:input_type -> gofeedforward.predictor.PredictRequest
+	6, // 5: gofeedforward.predictor.Predictor.ModelInfo:input_type -> gofeedforward.predictor.ModelInfoRequest
+	0, // 6: gofeedforward.predictor.Predictor.PredictStream:input_type -> gofeedforward.predictor.Example
+	0, // 7: gofeedforward.predictor.Predictor.ClassifyStream:input_type -> gofeedforward.predictor.Example
+	3, // 8: gofeedforward.predictor.Predictor.Predict:output_type -> gofeedforward.predictor.PredictResponse
+	5, // 9: gofeedforward.predictor.Predictor.Classify:output_type -> gofeedforward.predictor.ClassifyResponse
+	7, // 10: gofeedforward.predictor.Predictor.ModelInfo:output_type -> gofeedforward.predictor.ModelInfoResponse
+	2, // 11: gofeedforward.predictor.Predictor.PredictStream:output_type -> gofeedforward.predictor.Prediction
+	4, // 12: gofeedforward.predictor.Predictor.ClassifyStream:output_type -> gofeedforward.predictor.Classification
+	8, // [8:13] is the sub-list for method output_type
+	3, // [3:8] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_predictor_proto_init() }
+func file_predictor_proto_init() {
+	if File_predictor_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_predictor_proto_rawDesc), len(file_predictor_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_predictor_proto_goTypes,
+		DependencyIndexes: file_predictor_proto_depIdxs,
+		MessageInfos:      file_predictor_proto_msgTypes,
+	}.Build()
+	File_predictor_proto = out.File
+	file_predictor_proto_goTypes = nil
+	file_predictor_proto_depIdxs = nil
+}
@@ -0,0 +1,307 @@
+//
+//BSD 2-Clause License
+//
+//Copyright (c) 2016, Darc Inc
+//All rights reserved.
+//
+//Redistribution and use in source and binary forms, with or without
+//modification, are permitted provided that the following conditions are met:
+//
+// Redistributions of source code must retain the above copyright notice, this
+//list of conditions and the following disclaimer.
+//
+// Redistributions in binary form must reproduce the above copyright notice,
+//this list of conditions and the following disclaimer in the documentation
+//and/or other materials provided with the distribution.
+//
+//THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+//AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+//IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+//DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+//FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+//DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+//SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+//CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+//OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+//OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: predictor.proto
+
+package predictor
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Predictor_Predict_FullMethodName        = "/gofeedforward.predictor.Predictor/Predict"
+	Predictor_Classify_FullMethodName       = "/gofeedforward.predictor.Predictor/Classify"
+	Predictor_ModelInfo_FullMethodName      = "/gofeedforward.predictor.Predictor/ModelInfo"
+	Predictor_PredictStream_FullMethodName  = "/gofeedforward.predictor.Predictor/PredictStream"
+	Predictor_ClassifyStream_FullMethodName = "/gofeedforward.predictor.Predictor/ClassifyStream"
+)
+
+// PredictorClient is the client API for Predictor service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Predictor serves predictions from a trained gofeedforward pipeline.
+type PredictorClient interface {
+	// Predict returns the network outputs for each example, in their original
+	// units if the pipeline has an output scaler.
+	Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictResponse, error)
+	// Classify returns the classes for each example.
+	Classify(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*ClassifyResponse, error)
+	// ModelInfo describes the model being served.
+	ModelInfo(ctx context.Context, in *ModelInfoRequest, opts ...grpc.CallOption) (*ModelInfoResponse, error)
+	// PredictStream returns one prediction for each example sent, in order.
+	PredictStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Example, Prediction], error)
+	// ClassifyStream returns one classification for each example sent, in
+	// order.
+	ClassifyStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Example, Classification], error)
+}
+
+type predictorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPredictorClient(cc grpc.ClientConnInterface) PredictorClient {
+	return &predictorClient{cc}
+}
+
+func (c *predictorClient) Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PredictResponse)
+	err := c.cc.Invoke(ctx, Predictor_Predict_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *predictorClient) Classify(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*ClassifyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClassifyResponse)
+	err := c.cc.Invoke(ctx, Predictor_Classify_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *predictorClient) ModelInfo(ctx context.Context, in *ModelInfoRequest, opts ...grpc.CallOption) (*ModelInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ModelInfoResponse)
+	err := c.cc.Invoke(ctx, Predictor_ModelInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *predictorClient) PredictStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Example, Prediction], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Predictor_ServiceDesc.Streams[0], Predictor_PredictStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Example, Prediction]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Predictor_PredictStreamClient = grpc.BidiStreamingClient[Example, Prediction]
+
+func (c *predictorClient) ClassifyStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Example, Classification], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Predictor_ServiceDesc.Streams[1], Predictor_ClassifyStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Example, Classification]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Predictor_ClassifyStreamClient = grpc.BidiStreamingClient[Example, Classification]
+
+// PredictorServer is the server API for Predictor service.
+// All implementations must embed UnimplementedPredictorServer
+// for forward compatibility.
+//
+// Predictor serves predictions from a trained gofeedforward pipeline.
+type PredictorServer interface {
+	// Predict returns the network outputs for each example, in their original
+	// units if the pipeline has an output scaler.
+	Predict(context.Context, *PredictRequest) (*PredictResponse, error)
+	// Classify returns the classes for each example.
+	Classify(context.Context, *PredictRequest) (*ClassifyResponse, error)
+	// ModelInfo describes the model being served.
+	ModelInfo(context.Context, *ModelInfoRequest) (*ModelInfoResponse, error)
+	// PredictStream returns one prediction for each example sent, in order.
+	PredictStream(grpc.BidiStreamingServer[Example, Prediction]) error
+	// ClassifyStream returns one classification for each example sent, in
+	// order.
+	ClassifyStream(grpc.BidiStreamingServer[Example, Classification]) error
+	mustEmbedUnimplementedPredictorServer()
+}
+
+// UnimplementedPredictorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPredictorServer struct{}
+
+func (UnimplementedPredictorServer) Predict(context.Context, *PredictRequest) (*PredictResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Predict not implemented")
+}
+func (UnimplementedPredictorServer) Classify(context.Context, *PredictRequest) (*ClassifyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Classify not implemented")
+}
+func (UnimplementedPredictorServer) ModelInfo(context.Context, *ModelInfoRequest) (*ModelInfoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ModelInfo not implemented")
+}
+func (UnimplementedPredictorServer) PredictStream(grpc.BidiStreamingServer[Example, Prediction]) error {
+	return status.Error(codes.Unimplemented, "method PredictStream not implemented")
+}
+func (UnimplementedPredictorServer) ClassifyStream(grpc.BidiStreamingServer[Example, Classification]) error {
+	return status.Error(codes.Unimplemented, "method ClassifyStream not implemented")
+}
+func (UnimplementedPredictorServer) mustEmbedUnimplementedPredictorServer() {}
+func (UnimplementedPredictorServer) testEmbeddedByValue()                   {}
+
+// UnsafePredictorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PredictorServer will
+// result in compilation errors.
+type UnsafePredictorServer interface {
+	mustEmbedUnimplementedPredictorServer()
+}
+
+func RegisterPredictorServer(s grpc.ServiceRegistrar, srv PredictorServer) {
+	// If the following call panics, it indicates UnimplementedPredictorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Predictor_ServiceDesc, srv)
+}
+
+func _Predictor_Predict_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PredictRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PredictorServer).Predict(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Predictor_Predict_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PredictorServer).Predict(ctx, req.(*PredictRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Predictor_Classify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PredictRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PredictorServer).Classify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Predictor_Classify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PredictorServer).Classify(ctx, req.(*PredictRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Predictor_ModelInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModelInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PredictorServer).ModelInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Predictor_ModelInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PredictorServer).ModelInfo(ctx, req.(*ModelInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Predictor_PredictStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PredictorServer).PredictStream(&grpc.GenericServerStream[Example, Prediction]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Predictor_PredictStreamServer = grpc.BidiStreamingServer[Example, Prediction]
+
+func _Predictor_ClassifyStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PredictorServer).ClassifyStream(&grpc.GenericServerStream[Example, Classification]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Predictor_ClassifyStreamServer = grpc.BidiStreamingServer[Example, Classification]
+
+// Predictor_ServiceDesc is the grpc.ServiceDesc for Predictor service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Predictor_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gofeedforward.predictor.Predictor",
+	HandlerType: (*PredictorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Predict",
+			Handler:    _Predictor_Predict_Handler,
+		},
+		{
+			MethodName: "Classify",
+			Handler:    _Predictor_Classify_Handler,
+		},
+		{
+			MethodName: "ModelInfo",
+			Handler:    _Predictor_ModelInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PredictStream",
+			Handler:       _Predictor_PredictStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ClassifyStream",
+			Handler:       _Predictor_ClassifyStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "predictor.proto",
+}
@@ -0,0 +1,246 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package predictor serves predictions from a gofeedforward Pipeline over
+// gRPC.  The service is defined in predictor.proto; regenerate the .pb.go files
+// with go generate after changing it.
+package predictor
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative predictor.proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/DarcInc/gofeedforward"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements PredictorServer with a pipeline and an optional
+// classifier.  A network keeps the state of its last evaluation, so requests
+// take turns with a mutex.  Predict and Classify hold the mutex for the whole
+// request, while the streams take it for each example.
+type Server struct {
+	UnimplementedPredictorServer
+
+	mu         sync.Mutex
+	pipeline   gofeedforward.Pipeline
+	classifier gofeedforward.BasicClassifier
+}
+
+// MakeServer returns a server for the pipeline.  The classifier may be nil, in
+// which case Classify and ClassifyStream fail with FailedPrecondition.
+func MakeServer(pipeline gofeedforward.Pipeline, classifier gofeedforward.BasicClassifier) *Server {
+	return &Server{pipeline: pipeline, classifier: classifier}
+}
+
+// Update replaces the pipeline and classifier being served.  Predict and
+// Classify requests already in progress finish with the old ones; a stream
+// already in progress uses the new ones from its next example.
+func (s *Server) Update(pipeline gofeedforward.Pipeline, classifier gofeedforward.BasicClassifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pipeline = pipeline
+	s.classifier = classifier
+}
+
+// process turns an example into network inputs and returns the raw network
+// outputs.  Inputs skip the imputers and scalers, so a pipeline with either
+// only accepts records.  The caller must hold the mutex.
+func (s *Server) process(example *Example) ([]float64, error) {
+	inputs := example.GetInputs()
+	if record := example.GetRecord(); len(record) == 0 {
+		if len(inputs) > 0 && (len(s.pipeline.Imputers) > 0 || len(s.pipeline.Scalers) > 0) {
+			return nil, fmt.Errorf("model has imputers or scalers, so it only accepts records")
+		}
+	} else {
+		if len(inputs) > 0 {
+			return nil, fmt.Errorf("send either inputs or a record, not both")
+		}
+
+		if len(s.pipeline.Fields) == 0 {
+			return nil, fmt.Errorf("model has no fields, so it only accepts inputs")
+		}
+
+		var err error
+		if inputs, err = s.pipeline.Inputs(record); err != nil {
+			return nil, err
+		}
+	}
+	return s.pipeline.Network.Process(inputs)
+}
+
+// predict returns the outputs for the example in their original units.  The
+// caller must hold the mutex.
+func (s *Server) predict(example *Example) (*Prediction, error) {
+	outputs, err := s.process(example)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if s.pipeline.OutputScaler != nil {
+		if outputs, err = s.pipeline.OutputScaler.Inverse(outputs); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	return &Prediction{Outputs: append([]float64{}, outputs...)}, nil
+}
+
+// classify returns the classes for the example.  The caller must hold the
+// mutex.
+func (s *Server) classify(example *Example) (*Classification, error) {
+	if s.classifier == nil {
+		return nil, status.Error(codes.FailedPrecondition, "model has no classifier")
+	}
+
+	outputs, err := s.process(example)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	classes, err := s.classifier(outputs)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &Classification{Classes: classes}, nil
+}
+
+// exampleError adds the index of the failing example to a status error.
+func exampleError(idx int, err error) error {
+	st := status.Convert(err)
+	return status.Errorf(st.Code(), "example %d: %s", idx, st.Message())
+}
+
+// Predict returns the outputs for each example in the request.
+func (s *Server) Predict(ctx context.Context, req *PredictRequest) (*PredictResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &PredictResponse{}
+	for idx, example := range req.GetExamples() {
+		prediction, err := s.predict(example)
+		if err != nil {
+			return nil, exampleError(idx, err)
+		}
+		resp.Predictions = append(resp.Predictions, prediction)
+	}
+	return resp, nil
+}
+
+// Classify returns the classes for each example in the request.
+func (s *Server) Classify(ctx context.Context, req *PredictRequest) (*ClassifyResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &ClassifyResponse{}
+	for idx, example := range req.GetExamples() {
+		classification, err := s.classify(example)
+		if err != nil {
+			return nil, exampleError(idx, err)
+		}
+		resp.Classifications = append(resp.Classifications, classification)
+	}
+	return resp, nil
+}
+
+// ModelInfo describes the pipeline being served.
+func (s *Server) ModelInfo(ctx context.Context, req *ModelInfoRequest) (*ModelInfoResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &ModelInfoResponse{CanClassify: s.classifier != nil}
+	if len(s.pipeline.Network.Layers) > 0 {
+		resp.Sizes = append(resp.Sizes, int32(s.pipeline.Network.InputSize()))
+	}
+
+	for _, layer := range s.pipeline.Network.Layers {
+		resp.Sizes = append(resp.Sizes, int32(len(layer.Weights)))
+	}
+
+	for _, field := range s.pipeline.Fields {
+		resp.Fields = append(resp.Fields, field.Name)
+	}
+
+	if s.pipeline.Classifier != nil {
+		resp.Classes = s.pipeline.Classifier.Classes
+	}
+	return resp, nil
+}
+
+// PredictStream sends a prediction for each example received until the client
+// closes its side of the stream.
+func (s *Server) PredictStream(stream Predictor_PredictStreamServer) error {
+	for idx := 0; ; idx++ {
+		example, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		prediction, err := s.predict(example)
+		s.mu.Unlock()
+		if err != nil {
+			return exampleError(idx, err)
+		}
+
+		if err := stream.Send(prediction); err != nil {
+			return err
+		}
+	}
+}
+
+// ClassifyStream sends a classification for each example received until the
+// client closes its side of the stream.
+func (s *Server) ClassifyStream(stream Predictor_ClassifyStreamServer) error {
+	for idx := 0; ; idx++ {
+		example, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		classification, err := s.classify(example)
+		s.mu.Unlock()
+		if err != nil {
+			return exampleError(idx, err)
+		}
+
+		if err := stream.Send(classification); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,300 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package predictor
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/DarcInc/gofeedforward"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func testPipeline() gofeedforward.Pipeline {
+	colors := gofeedforward.FitOneHotEncoder([]string{"red", "blue"}, 1.0, 0.0)
+	net := gofeedforward.MakeNetwork(3, 2)
+	net.Layers[0].Weights[0] = []float64{4.0, -4.0, 0.0, 0.0}
+	net.Layers[0].Weights[1] = []float64{-4.0, 4.0, 0.0, 0.0}
+
+	return gofeedforward.Pipeline{
+		Fields:     []gofeedforward.Field{{Name: "color", OneHot: &colors}, {Name: "size"}},
+		Network:    net,
+		Classifier: &gofeedforward.ClassifierSpec{Type: "best", Classes: []string{"warm", "cool"}},
+	}
+}
+
+// testClient starts the server on an in-process listener and returns a client
+// connected to it.
+func testClient(t *testing.T, server *Server) PredictorClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	RegisterPredictorServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewPredictorClient(conn)
+}
+
+func testServer(t *testing.T) PredictorClient {
+	pipeline := testPipeline()
+	classifier, err := pipeline.Classifier.Classifier()
+	if err != nil {
+		t.Fatalf("Failed to make classifier: %v", err)
+	}
+	return testClient(t, MakeServer(pipeline, classifier))
+}
+
+func TestServer_Predict(t *testing.T) {
+	client := testServer(t)
+
+	resp, err := client.Predict(context.Background(), &PredictRequest{Examples: []*Example{
+		{Inputs: []float64{0.0, 1.0, 5.0}},
+		{Record: []string{"blue", "5"}},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to predict: %v", err)
+	}
+
+	predictions := resp.GetPredictions()
+	if len(predictions) != 2 {
+		t.Fatalf("Expected 2 predictions but got %d", len(predictions))
+	}
+
+	if predictions[0].Outputs[1] < 0.9 || predictions[1].Outputs[0] < 0.9 {
+		t.Errorf("Unexpected predictions %v", predictions)
+	}
+}
+
+func TestServer_PredictInvalid(t *testing.T) {
+	client := testServer(t)
+
+	_, err := client.Predict(context.Background(), &PredictRequest{Examples: []*Example{
+		{Record: []string{"blue", "5"}},
+		{Record: []string{"green", "5"}},
+	}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument for an unknown category but got %v", err)
+	}
+
+	_, err = client.Predict(context.Background(), &PredictRequest{Examples: []*Example{
+		{Inputs: []float64{1.0, 0.0, 5.0}, Record: []string{"blue", "5"}},
+	}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for inputs and a record but got %v", err)
+	}
+
+	_, err = client.Predict(context.Background(), &PredictRequest{Examples: []*Example{
+		{Inputs: []float64{1.0}},
+	}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for too few inputs but got %v", err)
+	}
+
+	pipeline := testPipeline()
+	pipeline.Scalers = []gofeedforward.Scaler{{Method: gofeedforward.ZScoreScaling, Columns: []int{2}, Center: []float64{5.0}, Spread: []float64{1.0}}}
+	_, err = MakeServer(pipeline, nil).Predict(context.Background(), &PredictRequest{Examples: []*Example{
+		{Inputs: []float64{1.0, 0.0, 5.0}},
+	}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for inputs to a pipeline with scalers but got %v", err)
+	}
+}
+
+func TestServer_Classify(t *testing.T) {
+	client := testServer(t)
+
+	resp, err := client.Classify(context.Background(), &PredictRequest{Examples: []*Example{
+		{Record: []string{"red", "5"}},
+		{Record: []string{"blue", "5"}},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to classify: %v", err)
+	}
+
+	classifications := resp.GetClassifications()
+	if len(classifications) != 2 || classifications[0].Classes[0] != "cool" || classifications[1].Classes[0] != "warm" {
+		t.Errorf("Unexpected classifications %v", classifications)
+	}
+
+	noClassifier := testClient(t, MakeServer(testPipeline(), nil))
+	_, err = noClassifier.Classify(context.Background(), &PredictRequest{Examples: []*Example{
+		{Record: []string{"red", "5"}},
+	}})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition without a classifier but got %v", err)
+	}
+}
+
+func TestServer_ModelInfo(t *testing.T) {
+	client := testServer(t)
+
+	info, err := client.ModelInfo(context.Background(), &ModelInfoRequest{})
+	if err != nil {
+		t.Fatalf("Failed to get model info: %v", err)
+	}
+
+	sizes := info.GetSizes()
+	if len(sizes) != 2 || sizes[0] != 3 || sizes[1] != 2 {
+		t.Errorf("Expected sizes [3 2] but got %v", sizes)
+	}
+
+	fields := info.GetFields()
+	if len(fields) != 2 || fields[0] != "color" || fields[1] != "size" {
+		t.Errorf("Expected fields [color size] but got %v", fields)
+	}
+
+	if !info.GetCanClassify() || len(info.GetClasses()) != 2 {
+		t.Errorf("Expected a classifier with 2 classes but got %v", info)
+	}
+}
+
+func TestServer_PredictStream(t *testing.T) {
+	client := testServer(t)
+
+	stream, err := client.PredictStream(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+
+	records := [][]string{{"red", "1"}, {"blue", "2"}, {"red", "3"}}
+	go func() {
+		for _, record := range records {
+			stream.Send(&Example{Record: record})
+		}
+		stream.CloseSend()
+	}()
+
+	count := 0
+	for {
+		prediction, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Failed to receive prediction: %v", err)
+		}
+
+		warm := records[count][0] == "blue"
+		if (prediction.Outputs[0] > 0.5) != warm {
+			t.Errorf("Unexpected prediction %v for %v", prediction.Outputs, records[count])
+		}
+		count++
+	}
+
+	if count != len(records) {
+		t.Errorf("Expected %d predictions but got %d", len(records), count)
+	}
+}
+
+func TestServer_ClassifyStream(t *testing.T) {
+	client := testServer(t)
+
+	stream, err := client.ClassifyStream(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+
+	if err := stream.Send(&Example{Record: []string{"red", "1"}}); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+
+	classification, err := stream.Recv()
+	if err != nil || classification.Classes[0] != "cool" {
+		t.Fatalf("Expected cool but got %v %v", classification, err)
+	}
+
+	stream.Send(&Example{Record: []string{"green", "1"}})
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected the stream to end with InvalidArgument but got %v", err)
+	}
+}
+
+func TestServer_Update(t *testing.T) {
+	server := MakeServer(testPipeline(), nil)
+	client := testClient(t, server)
+
+	pipeline := testPipeline()
+	pipeline.Network.Layers[0].Weights[0] = []float64{-4.0, 4.0, 0.0, 0.0}
+	server.Update(pipeline, nil)
+
+	resp, err := client.Predict(context.Background(), &PredictRequest{Examples: []*Example{
+		{Record: []string{"blue", "5"}},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to predict: %v", err)
+	}
+
+	if resp.Predictions[0].Outputs[0] > 0.1 {
+		t.Errorf("Expected the updated network to be used but got %v", resp.Predictions[0].Outputs)
+	}
+}
+
+func TestServer_UpdateDuringPredict(t *testing.T) {
+	server := MakeServer(testPipeline(), nil)
+
+	examples := make([]*Example, 2000)
+	for idx := range examples {
+		examples[idx] = &Example{Record: []string{"blue", "5"}}
+	}
+
+	pipeline := testPipeline()
+	pipeline.Network.Layers[0].Weights[0] = []float64{-4.0, 4.0, 0.0, 0.0}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server.Update(pipeline, nil)
+	}()
+
+	resp, err := server.Predict(context.Background(), &PredictRequest{Examples: examples})
+	<-done
+	if err != nil {
+		t.Fatalf("Failed to predict: %v", err)
+	}
+
+	for idx, prediction := range resp.Predictions {
+		if prediction.Outputs[0] != resp.Predictions[0].Outputs[0] {
+			t.Fatalf("Expected one model for the whole request but example %d got %v instead of %v",
+				idx, prediction.Outputs, resp.Predictions[0].Outputs)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"log/slog"
+	"time"
+)
+
+// AddProgressLogger registers callbacks that report training progress through
+// a structured logger.  A "training progress" event is logged at the Info level
+// every given number of iterations with the iteration, the loss, the mean
+// squared error for each output and the elapsed time.  If maxIter is greater
+// than zero, the event also includes an estimate of the time remaining until
+// maxIter is reached, which is usually the same limit given to
+// AddSimpleStoppingCriteria.  Events are also logged when training starts and
+// ends, and an iteration that fails is logged at the Error level.  A nil logger
+// uses slog.Default().
+func (t *Trainer) AddProgressLogger(logger *slog.Logger, every, maxIter int) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if every < 1 {
+		every = 1
+	}
+
+	start := time.Now()
+	iterations := 0
+	var lastLoss float64
+
+	t.AddTrainingBeginHandler(func(t *Trainer) {
+		start = time.Now()
+		iterations = 0
+		logger.Info("training started", "alpha", t.Alpha, "batch_update", t.BatchUpdate,
+			"shuffle_rounds", t.ShuffleRounds, "max_iterations", maxIter)
+	})
+
+	t.AddIterationEndHandler(func(t *Trainer, mse SquaredError, iter int, err error) {
+		iterations = iter
+		if err != nil {
+			logger.Error("training iteration failed", "iteration", iter, "error", err)
+			return
+		}
+
+		lastLoss = t.Loss(mse)
+		if iter%every != 0 {
+			return
+		}
+
+		elapsed := time.Since(start)
+		attrs := []any{
+			"iteration", iter,
+			"loss", lastLoss,
+			"errors", []float64(mse),
+			"elapsed", elapsed,
+		}
+
+		if maxIter > 0 && iter < maxIter {
+			eta := time.Duration(float64(elapsed) / float64(iter) * float64(maxIter-iter))
+			attrs = append(attrs, "eta", eta)
+		}
+		logger.Info("training progress", attrs...)
+	})
+
+	t.AddTrainingEndHandler(func(t *Trainer) {
+		logger.Info("training finished", "iterations", iterations, "loss", lastLoss,
+			"elapsed", time.Since(start))
+	})
+}
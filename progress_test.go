@@ -0,0 +1,85 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestTrainer_AddProgressLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	net := MakeNetwork(2, 3, 1)
+	net.Randomize()
+
+	trainer := Trainer{}
+	trainer.AddProgressLogger(logger, 5, 20)
+	trainer.AddSimpleStoppingCriteria(19, 0.0)
+	trainer.Train(&net, xorData())
+
+	events := []map[string]any{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		event := map[string]any{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Failed to parse log line %s: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+
+	if len(events) != 6 {
+		t.Fatalf("Expected a start, 4 progress and an end event but got %d", len(events))
+	}
+
+	if events[0]["msg"] != "training started" || events[5]["msg"] != "training finished" {
+		t.Errorf("Unexpected first and last events %v, %v", events[0], events[5])
+	}
+
+	progress := events[2]
+	if progress["msg"] != "training progress" || progress["iteration"] != 10.0 {
+		t.Errorf("Expected progress at iteration 10 but got %v", progress)
+	}
+
+	if _, ok := progress["eta"]; !ok {
+		t.Errorf("Expected an ETA but got %v", progress)
+	}
+
+	if errors, ok := progress["errors"].([]any); !ok || len(errors) != 1 {
+		t.Errorf("Expected the error for each output but got %v", progress["errors"])
+	}
+
+	if _, ok := events[4]["eta"]; ok {
+		t.Errorf("Expected no ETA at the last iteration but got %v", events[4])
+	}
+}
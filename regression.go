@@ -0,0 +1,219 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Prediction pairs the expected values for an example with the outputs the
+// network produced for it.
+type Prediction struct {
+	Expected []float64
+	Outputs  []float64
+}
+
+// Predictions is the collection of predictions when the network is applied to
+// a data set.  Unlike AllErrors it keeps the outputs themselves, so it can be
+// used to calculate metrics that depend on the sign of the error or on the
+// expected values.
+type Predictions []Prediction
+
+// RegressionMetrics summarizes the error for a single network output.  The
+// residual is the expected value minus the network output.  MAPE is the mean
+// absolute percentage error and skips any example with an expected value of
+// zero; it is NaN if every expected value is zero.  RSquared and
+// ExplainedVariance are undefined when every expected value is the same, so
+// they are 1.0 if the outputs fit the expected values exactly (or, for
+// ExplainedVariance, are off by a constant) and 0.0 otherwise.
+// ResidualQuantiles holds the residual at each of the quantiles requested.
+type RegressionMetrics struct {
+	RMSE              float64
+	MAE               float64
+	MAPE              float64
+	RSquared          float64
+	ExplainedVariance float64
+	ResidualQuantiles []float64
+}
+
+// EvaluatePredictions executes the network for each example in the training
+// data and returns the expected values and outputs for each example.
+func EvaluatePredictions(net Network, td TrainingData) (Predictions, error) {
+	result := Predictions{}
+	for _, datum := range td {
+		output, err := net.Process(datum.Inputs)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(datum.Expected) != len(output) {
+			return nil, fmt.Errorf("Expected length = %d actual length = %d", len(datum.Expected), len(output))
+		}
+		result = append(result, Prediction{Expected: datum.Expected, Outputs: output})
+	}
+	return result, nil
+}
+
+// Errors returns the squared errors for each prediction, the same values that
+// Evaluate returns.
+func (p Predictions) Errors() AllErrors {
+	result := AllErrors{}
+	for _, prediction := range p {
+		se, _ := CalcError(prediction.Expected, prediction.Outputs)
+		result = append(result, se)
+	}
+	return result
+}
+
+// Residuals returns the residual, the expected value minus the output, for the
+// given output of every prediction.
+func (p Predictions) Residuals(output int) []float64 {
+	result := make([]float64, len(p))
+	for idx, prediction := range p {
+		result[idx] = prediction.Expected[output] - prediction.Outputs[output]
+	}
+	return result
+}
+
+// RegressionMetrics calculates the regression metrics for each network output.
+// The quantiles are values between 0.0 and 1.0, for example 0.5 for the median
+// residual, and are interpolated between the nearest residuals.
+func (p Predictions) RegressionMetrics(quantiles ...float64) ([]RegressionMetrics, error) {
+	if len(p) == 0 {
+		return nil, fmt.Errorf("Unable to calculate regression metrics without predictions")
+	}
+
+	for _, q := range quantiles {
+		if q < 0.0 || q > 1.0 {
+			return nil, fmt.Errorf("Quantiles must be between 0.0 and 1.0, not: %0.4f", q)
+		}
+	}
+
+	result := make([]RegressionMetrics, len(p[0].Outputs))
+	for output := range result {
+		residuals := p.Residuals(output)
+		expected := make([]float64, len(p))
+		for idx, prediction := range p {
+			expected[idx] = prediction.Expected[output]
+		}
+
+		metrics := RegressionMetrics{}
+		sumSquares, sumPercent, percentCount := 0.0, 0.0, 0
+		for idx, residual := range residuals {
+			sumSquares += residual * residual
+			metrics.MAE += math.Abs(residual)
+			if expected[idx] != 0.0 {
+				sumPercent += math.Abs(residual / expected[idx])
+				percentCount++
+			}
+		}
+
+		n := float64(len(p))
+		metrics.RMSE = math.Sqrt(sumSquares / n)
+		metrics.MAE /= n
+		metrics.MAPE = math.NaN()
+		if percentCount > 0 {
+			metrics.MAPE = 100.0 * sumPercent / float64(percentCount)
+		}
+
+		expectedVariance := variance(expected)
+		metrics.RSquared = fractionExplained(sumSquares/n, expectedVariance)
+		metrics.ExplainedVariance = fractionExplained(variance(residuals), expectedVariance)
+
+		sort.Float64s(residuals)
+		for _, q := range quantiles {
+			metrics.ResidualQuantiles = append(metrics.ResidualQuantiles, quantile(residuals, q))
+		}
+		result[output] = metrics
+	}
+	return result, nil
+}
+
+// WriteResidualsCSV writes one row per example and output with the network
+// output, the expected value and the residual, which is convenient for plotting
+// residuals against predictions.
+func (p Predictions) WriteResidualsCSV(out io.Writer) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"example", "output", "predicted", "expected", "residual"})
+
+	for idx, prediction := range p {
+		for output := range prediction.Outputs {
+			w.Write([]string{
+				strconv.Itoa(idx),
+				strconv.Itoa(output),
+				strconv.FormatFloat(prediction.Outputs[output], 'g', -1, 64),
+				strconv.FormatFloat(prediction.Expected[output], 'g', -1, 64),
+				strconv.FormatFloat(prediction.Expected[output]-prediction.Outputs[output], 'g', -1, 64),
+			})
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func variance(values []float64) float64 {
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return sum / float64(len(values))
+}
+
+// quantile interpolates the given quantile from a sorted slice of values.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	low := int(math.Floor(pos))
+	high := int(math.Ceil(pos))
+	return sorted[low] + (pos-float64(low))*(sorted[high]-sorted[low])
+}
+
+// fractionExplained returns 1.0 minus the unexplained variance as a fraction
+// of the expected variance.  If the expected values are constant, the fraction
+// is undefined, so it is 1.0 for a perfect fit and 0.0 otherwise.
+func fractionExplained(unexplained, expectedVariance float64) float64 {
+	if expectedVariance == 0.0 {
+		if unexplained == 0.0 {
+			return 1.0
+		}
+		return 0.0
+	}
+	return 1.0 - unexplained/expectedVariance
+}
@@ -0,0 +1,123 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRegressionMetrics(t *testing.T) {
+	p := Predictions{
+		Prediction{Expected: []float64{1.0}, Outputs: []float64{1.5}},
+		Prediction{Expected: []float64{2.0}, Outputs: []float64{2.0}},
+		Prediction{Expected: []float64{3.0}, Outputs: []float64{2.0}},
+		Prediction{Expected: []float64{4.0}, Outputs: []float64{4.5}},
+	}
+
+	metrics, err := p.RegressionMetrics(0.0, 0.5, 1.0)
+	if err != nil {
+		t.Errorf("Failed to calculate metrics: %v", err)
+	}
+
+	m := metrics[0]
+	if outOfBoundsCheck(0.6124, m.RMSE, 0.0001) {
+		t.Errorf("Expected RMSE of 0.6124 but got %0.4f", m.RMSE)
+	}
+
+	if outOfBoundsCheck(0.5, m.MAE, 0.0001) {
+		t.Errorf("Expected MAE of 0.5 but got %0.4f", m.MAE)
+	}
+
+	if outOfBoundsCheck(23.9583, m.MAPE, 0.0001) {
+		t.Errorf("Expected MAPE of 23.9583 but got %0.4f", m.MAPE)
+	}
+
+	if outOfBoundsCheck(0.7, m.RSquared, 0.0001) {
+		t.Errorf("Expected R squared of 0.7 but got %0.4f", m.RSquared)
+	}
+
+	if outOfBoundsCheck(0.7, m.ExplainedVariance, 0.0001) {
+		t.Errorf("Expected explained variance of 0.7 but got %0.4f", m.ExplainedVariance)
+	}
+
+	if outOfBoundsCheck(-0.5, m.ResidualQuantiles[0], 0.0001) || outOfBoundsCheck(-0.25, m.ResidualQuantiles[1], 0.0001) ||
+		outOfBoundsCheck(1.0, m.ResidualQuantiles[2], 0.0001) {
+		t.Errorf("Unexpected residual quantiles %v", m.ResidualQuantiles)
+	}
+
+	if _, err := p.RegressionMetrics(1.5); err == nil {
+		t.Error("Expected an error for a quantile above 1.0")
+	}
+}
+
+func TestRegressionMetrics_ConstantExpected(t *testing.T) {
+	p := Predictions{
+		Prediction{Expected: []float64{2.0, 1.0}, Outputs: []float64{2.0, 1.5}},
+		Prediction{Expected: []float64{2.0, 1.0}, Outputs: []float64{2.0, 0.5}},
+	}
+
+	metrics, err := p.RegressionMetrics()
+	if err != nil {
+		t.Fatalf("Failed to calculate metrics: %v", err)
+	}
+
+	if metrics[0].RSquared != 1.0 || metrics[0].ExplainedVariance != 1.0 {
+		t.Errorf("Expected a perfect fit of a constant to score 1.0 but got %v", metrics[0])
+	}
+
+	if metrics[1].RSquared != 0.0 || metrics[1].ExplainedVariance != 0.0 {
+		t.Errorf("Expected an imperfect fit of a constant to score 0.0 but got %v", metrics[1])
+	}
+}
+
+func TestEvaluatePredictions(t *testing.T) {
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{1.0, 0.0}, Expected: []float64{0.5, 0.5}},
+		TrainingDatum{Inputs: []float64{2.0, 0.0}, Expected: []float64{0.4, 0.4}},
+	}
+
+	p, err := EvaluatePredictions(MakeNetwork(2, 2), td)
+	if err != nil {
+		t.Errorf("Error evaluating network: %v", err)
+	}
+
+	allErrors := p.Errors()
+	if outOfBoundsCheck(0.01, allErrors[1][0], 0.001) {
+		t.Errorf("Expected the same errors as Evaluate but got %v", allErrors)
+	}
+
+	var buf bytes.Buffer
+	p.WriteResidualsCSV(&buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || lines[3] != "1,0,0.5,0.4,-0.09999999999999998" {
+		t.Errorf("Unexpected residuals CSV:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,301 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// NoClass is the label used in a confusion matrix when a classifier returns no
+// class at all for an example, as a threshold classifier may.
+const NoClass = "(none)"
+
+// ClassMetrics holds the precision, recall and F1 score for a class along with
+// its support, which is the number of examples expected to be in the class.
+type ClassMetrics struct {
+	Precision float64
+	Recall    float64
+	F1        float64
+	Support   int
+}
+
+// ClassificationReport summarizes how well a network classifies a data set.
+// Confusion counts examples by expected label and then by actual label.  For a
+// classifier that returns a single class, the labels are simply the class
+// names.  A classifier that returns several classes for an example is recorded
+// under the class names joined with a comma and one that returns no classes is
+// recorded under NoClass.  The per class metrics count an example towards each
+// class it was expected or classified as, so they also apply to classifiers
+// that return several classes.  Examples is the number of examples and is the
+// support for the Accuracy.
+type ClassificationReport struct {
+	Confusion map[string]map[string]int
+	Classes   map[string]ClassMetrics
+	Macro     ClassMetrics
+	Micro     ClassMetrics
+	Weighted  ClassMetrics
+	Accuracy  float64
+	Examples  int
+}
+
+// MakeClassificationReport runs the network over each example in the data and
+// compares the classification of the expected outputs to the classification of
+// the actual outputs, in the same way as ClassificationError.
+func MakeClassificationReport(net Network, td TrainingData, classifier BasicClassifier) (ClassificationReport, error) {
+	report := ClassificationReport{Confusion: map[string]map[string]int{}, Classes: map[string]ClassMetrics{}}
+	truePositives := map[string]int{}
+	falsePositives := map[string]int{}
+	falseNegatives := map[string]int{}
+	correct := 0
+
+	for _, datum := range td {
+		expected, err := classifier(datum.Expected)
+		if err != nil {
+			return ClassificationReport{}, err
+		}
+
+		outputs, err := net.Process(datum.Inputs)
+		if err != nil {
+			return ClassificationReport{}, err
+		}
+
+		actual, err := classifier(outputs)
+		if err != nil {
+			return ClassificationReport{}, err
+		}
+
+		expectedLabel, actualLabel := confusionLabel(expected), confusionLabel(actual)
+		if report.Confusion[expectedLabel] == nil {
+			report.Confusion[expectedLabel] = map[string]int{}
+		}
+		report.Confusion[expectedLabel][actualLabel]++
+		if expectedLabel == actualLabel {
+			correct++
+		}
+
+		for _, class := range expected {
+			report.Classes[class] = ClassMetrics{}
+			if contains(actual, class) {
+				truePositives[class]++
+			} else {
+				falseNegatives[class]++
+			}
+		}
+
+		for _, class := range actual {
+			report.Classes[class] = ClassMetrics{}
+			if !contains(expected, class) {
+				falsePositives[class]++
+			}
+		}
+	}
+
+	totalTP, totalFP, totalFN := 0, 0, 0
+	for class := range report.Classes {
+		metrics := makeClassMetrics(truePositives[class], falsePositives[class], falseNegatives[class])
+		report.Classes[class] = metrics
+
+		report.Macro.Precision += metrics.Precision
+		report.Macro.Recall += metrics.Recall
+		report.Macro.F1 += metrics.F1
+		report.Macro.Support += metrics.Support
+
+		report.Weighted.Precision += metrics.Precision * float64(metrics.Support)
+		report.Weighted.Recall += metrics.Recall * float64(metrics.Support)
+		report.Weighted.F1 += metrics.F1 * float64(metrics.Support)
+		report.Weighted.Support += metrics.Support
+
+		totalTP += truePositives[class]
+		totalFP += falsePositives[class]
+		totalFN += falseNegatives[class]
+	}
+
+	if len(report.Classes) > 0 {
+		report.Macro.Precision /= float64(len(report.Classes))
+		report.Macro.Recall /= float64(len(report.Classes))
+		report.Macro.F1 /= float64(len(report.Classes))
+	}
+
+	if report.Weighted.Support > 0 {
+		report.Weighted.Precision /= float64(report.Weighted.Support)
+		report.Weighted.Recall /= float64(report.Weighted.Support)
+		report.Weighted.F1 /= float64(report.Weighted.Support)
+	}
+
+	report.Micro = makeClassMetrics(totalTP, totalFP, totalFN)
+	report.Examples = len(td)
+	if len(td) > 0 {
+		report.Accuracy = float64(correct) / float64(len(td))
+	}
+	return report, nil
+}
+
+func makeClassMetrics(truePositives, falsePositives, falseNegatives int) ClassMetrics {
+	result := ClassMetrics{Support: truePositives + falseNegatives}
+	if truePositives+falsePositives > 0 {
+		result.Precision = float64(truePositives) / float64(truePositives+falsePositives)
+	}
+	if truePositives+falseNegatives > 0 {
+		result.Recall = float64(truePositives) / float64(truePositives+falseNegatives)
+	}
+	if result.Precision+result.Recall > 0 {
+		result.F1 = 2 * result.Precision * result.Recall / (result.Precision + result.Recall)
+	}
+	return result
+}
+
+func confusionLabel(classes []string) string {
+	if len(classes) == 0 {
+		return NoClass
+	}
+	return strings.Join(classes, ",")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassNames returns the names of the classes in the report in sorted order.
+func (r ClassificationReport) ClassNames() []string {
+	names := []string{}
+	for class := range r.Classes {
+		names = append(names, class)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Labels returns every expected and actual label in the confusion matrix in
+// sorted order.
+func (r ClassificationReport) Labels() []string {
+	seen := map[string]bool{}
+	for expected, row := range r.Confusion {
+		seen[expected] = true
+		for actual := range row {
+			seen[actual] = true
+		}
+	}
+
+	labels := []string{}
+	for label := range seen {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// String renders the report as a plain text table of the per class metrics and
+// averages followed by the confusion matrix, with expected labels as rows and
+// actual labels as columns.
+func (r ClassificationReport) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(w, "class\tprecision\trecall\tf1\tsupport\t")
+	for _, class := range r.ClassNames() {
+		writeMetricsRow(w, class, r.Classes[class])
+	}
+	fmt.Fprintln(w, "\t\t\t\t\t")
+	writeMetricsRow(w, "micro avg", r.Micro)
+	writeMetricsRow(w, "macro avg", r.Macro)
+	writeMetricsRow(w, "weighted avg", r.Weighted)
+	fmt.Fprintf(w, "accuracy\t\t\t%0.4f\t%d\t\n", r.Accuracy, r.Examples)
+	w.Flush()
+
+	buf.WriteString("\n")
+	w = tabwriter.NewWriter(&buf, 0, 0, 2, ' ', tabwriter.AlignRight)
+	labels := r.Labels()
+	fmt.Fprintf(w, "expected \\ actual\t%s\t\n", strings.Join(labels, "\t"))
+	for _, expected := range labels {
+		fmt.Fprintf(w, "%s\t", expected)
+		for _, actual := range labels {
+			fmt.Fprintf(w, "%d\t", r.Confusion[expected][actual])
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+
+	return buf.String()
+}
+
+func writeMetricsRow(w io.Writer, name string, m ClassMetrics) {
+	fmt.Fprintf(w, "%s\t%0.4f\t%0.4f\t%0.4f\t%d\t\n", name, m.Precision, m.Recall, m.F1, m.Support)
+}
+
+// WriteCSV writes the per class metrics and the averages as CSV with a header
+// row.
+func (r ClassificationReport) WriteCSV(out io.Writer) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"class", "precision", "recall", "f1", "support"})
+
+	row := func(name string, m ClassMetrics) []string {
+		return []string{name, fmt.Sprintf("%g", m.Precision), fmt.Sprintf("%g", m.Recall),
+			fmt.Sprintf("%g", m.F1), fmt.Sprintf("%d", m.Support)}
+	}
+
+	for _, class := range r.ClassNames() {
+		w.Write(row(class, r.Classes[class]))
+	}
+	w.Write(row("micro avg", r.Micro))
+	w.Write(row("macro avg", r.Macro))
+	w.Write(row("weighted avg", r.Weighted))
+
+	w.Flush()
+	return w.Error()
+}
+
+// WriteConfusionCSV writes the confusion matrix as CSV.  The first row holds
+// the actual labels and each following row starts with an expected label.
+func (r ClassificationReport) WriteConfusionCSV(out io.Writer) error {
+	w := csv.NewWriter(out)
+	labels := r.Labels()
+	w.Write(append([]string{"expected"}, labels...))
+
+	for _, expected := range labels {
+		record := []string{expected}
+		for _, actual := range labels {
+			record = append(record, fmt.Sprintf("%d", r.Confusion[expected][actual]))
+		}
+		w.Write(record)
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,132 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func reportNetwork() (Network, TrainingData) {
+	net := MakeNetwork(2, 2)
+	net.Layers[0].Weights[0] = []float64{8.0, 0.0, -4.0}
+	net.Layers[0].Weights[1] = []float64{0.0, 8.0, -4.0}
+
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{1.0, 0.0}, Expected: []float64{0.9, 0.1}},
+		TrainingDatum{Inputs: []float64{1.0, 0.0}, Expected: []float64{0.9, 0.1}},
+		TrainingDatum{Inputs: []float64{0.0, 1.0}, Expected: []float64{0.9, 0.1}},
+		TrainingDatum{Inputs: []float64{0.0, 1.0}, Expected: []float64{0.1, 0.9}},
+		TrainingDatum{Inputs: []float64{0.0, 1.0}, Expected: []float64{0.1, 0.9}},
+	}
+	return net, td
+}
+
+func TestMakeClassificationReport(t *testing.T) {
+	net, td := reportNetwork()
+
+	report, err := MakeClassificationReport(net, td, MakeBestOfClassifier([]string{"a", "b"}))
+	if err != nil {
+		t.Errorf("Failed to make report: %v", err)
+	}
+
+	if report.Confusion["a"]["a"] != 2 || report.Confusion["a"]["b"] != 1 || report.Confusion["b"]["b"] != 2 {
+		t.Errorf("Unexpected confusion matrix %v", report.Confusion)
+	}
+
+	a := report.Classes["a"]
+	if outOfBoundsCheck(1.0, a.Precision, 0.001) || outOfBoundsCheck(0.6667, a.Recall, 0.001) ||
+		outOfBoundsCheck(0.8, a.F1, 0.001) || a.Support != 3 {
+		t.Errorf("Unexpected metrics for class a: %+v", a)
+	}
+
+	b := report.Classes["b"]
+	if outOfBoundsCheck(0.6667, b.Precision, 0.001) || outOfBoundsCheck(1.0, b.Recall, 0.001) || b.Support != 2 {
+		t.Errorf("Unexpected metrics for class b: %+v", b)
+	}
+
+	if outOfBoundsCheck(0.8, report.Accuracy, 0.001) || outOfBoundsCheck(0.8, report.Micro.Precision, 0.001) {
+		t.Errorf("Expected accuracy and micro precision of 0.8 but got %0.4f and %0.4f",
+			report.Accuracy, report.Micro.Precision)
+	}
+
+	if outOfBoundsCheck(0.8333, report.Macro.Precision, 0.001) || outOfBoundsCheck(0.8667, report.Weighted.Precision, 0.001) {
+		t.Errorf("Unexpected macro or weighted precision %0.4f %0.4f", report.Macro.Precision, report.Weighted.Precision)
+	}
+}
+
+func TestMakeClassificationReportNoClass(t *testing.T) {
+	net, _ := reportNetwork()
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{0.0, 0.0}, Expected: []float64{0.9, 0.1}},
+		TrainingDatum{Inputs: []float64{1.0, 1.0}, Expected: []float64{0.9, 0.1}},
+	}
+
+	report, _ := MakeClassificationReport(net, td, MakeThresholdClassifier([]string{"a", "b"}, 0.5))
+	if report.Confusion["a"][NoClass] != 1 || report.Confusion["a"]["a,b"] != 1 {
+		t.Errorf("Unexpected confusion matrix %v", report.Confusion)
+	}
+
+	td = append(td, TrainingDatum{Inputs: []float64{1.0, 1.0}, Expected: []float64{0.9, 0.9}})
+	report, _ = MakeClassificationReport(net, td, MakeThresholdClassifier([]string{"a", "b"}, 0.5))
+	if report.Examples != 3 || report.Weighted.Support != 4 {
+		t.Errorf("Expected 3 examples and a weighted support of 4 but got %d and %d", report.Examples, report.Weighted.Support)
+	}
+
+	for _, line := range strings.Split(report.String(), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == "accuracy" && fields[len(fields)-1] != "3" {
+			t.Errorf("Expected the accuracy support to be the 3 examples but got %q", line)
+		}
+	}
+}
+
+func TestClassificationReport_Render(t *testing.T) {
+	net, td := reportNetwork()
+	report, _ := MakeClassificationReport(net, td, MakeBestOfClassifier([]string{"a", "b"}))
+
+	if text := report.String(); !strings.Contains(text, "weighted avg") || !strings.Contains(text, "expected \\ actual") {
+		t.Errorf("Text report is missing sections:\n%s", text)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Errorf("Failed to write CSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 || lines[1] != "a,1,0.6666666666666666,0.8,3" {
+		t.Errorf("Unexpected CSV report:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	report.WriteConfusionCSV(&buf)
+	if buf.String() != "expected,a,b\na,2,1\nb,0,2\n" {
+		t.Errorf("Unexpected confusion CSV:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,286 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Transformer is a fitted transformation of a vector of values, such as a set
+// of network inputs.
+type Transformer interface {
+	Transform(values []float64) ([]float64, error)
+}
+
+// ScaleMethod names the way a Scaler scales its columns.
+type ScaleMethod string
+
+const (
+	// MinMaxScaling maps the minimum and maximum of each column to Low and High.
+	MinMaxScaling ScaleMethod = "minmax"
+
+	// ZScoreScaling subtracts the mean of each column and divides by the
+	// standard deviation.
+	ZScoreScaling ScaleMethod = "zscore"
+
+	// RobustScaling subtracts the median of each column and divides by the
+	// interquartile range, so it is not thrown off by a few outliers.
+	RobustScaling ScaleMethod = "robust"
+
+	// LogScaling takes the natural log of each value after shifting the column
+	// so its minimum is 1.0.  It compresses columns with a long tail.
+	LogScaling ScaleMethod = "log"
+)
+
+// Scaler scales the columns of a vector using values learned from training data
+// so the same scaling can be applied to validation data and live inputs, and
+// reversed on network outputs.  Columns lists the columns that are scaled and
+// Center and Spread hold the values learned for each of them.  A column with
+// no spread, such as a constant column, is only centered.  The fields are
+// exported so the scaler can be saved along with the network.
+type Scaler struct {
+	Method  ScaleMethod
+	Columns []int
+	Center  []float64
+	Spread  []float64
+	Low     float64
+	High    float64
+}
+
+// InputValues returns the inputs of every training datum, which can be used to
+// fit a scaler.
+func (td TrainingData) InputValues() [][]float64 {
+	result := make([][]float64, len(td))
+	for idx := range td {
+		result[idx] = td[idx].Inputs
+	}
+	return result
+}
+
+// ExpectedValues returns the expected values of every training datum, which can
+// be used to fit a scaler.
+func (td TrainingData) ExpectedValues() [][]float64 {
+	result := make([][]float64, len(td))
+	for idx := range td {
+		result[idx] = td[idx].Expected
+	}
+	return result
+}
+
+// FitMinMaxScaler creates a scaler that maps the minimum and maximum of each of
+// the given columns to low and high, where low must be less than high.  If no
+// columns are given every column is scaled.
+func FitMinMaxScaler(values [][]float64, low, high float64, columns ...int) (Scaler, error) {
+	if low >= high {
+		return Scaler{}, fmt.Errorf("Unable to scale between %v and %v, low must be less than high", low, high)
+	}
+
+	return fitScaler(MinMaxScaling, values, columns, func(column []float64) (float64, float64) {
+		return column[0], column[len(column)-1] - column[0]
+	}, low, high)
+}
+
+// FitZScoreScaler creates a scaler that gives each of the given columns a mean
+// of 0.0 and a standard deviation of 1.0.  If no columns are given every column
+// is scaled.
+func FitZScoreScaler(values [][]float64, columns ...int) (Scaler, error) {
+	return fitScaler(ZScoreScaling, values, columns, func(column []float64) (float64, float64) {
+		mean := 0.0
+		for _, v := range column {
+			mean += v
+		}
+		mean /= float64(len(column))
+		return mean, math.Sqrt(variance(column))
+	}, 0.0, 0.0)
+}
+
+// FitRobustScaler creates a scaler that centers each of the given columns on
+// its median and divides by its interquartile range.  If no columns are given
+// every column is scaled.
+func FitRobustScaler(values [][]float64, columns ...int) (Scaler, error) {
+	return fitScaler(RobustScaling, values, columns, func(column []float64) (float64, float64) {
+		return quantile(column, 0.5), quantile(column, 0.75) - quantile(column, 0.25)
+	}, 0.0, 0.0)
+}
+
+// FitLogScaler creates a scaler that takes the log of each of the given columns
+// after shifting it so the minimum is 1.0.  Values below the minimum seen when
+// fitting may produce NaN.  If no columns are given every column is scaled.
+func FitLogScaler(values [][]float64, columns ...int) (Scaler, error) {
+	return fitScaler(LogScaling, values, columns, func(column []float64) (float64, float64) {
+		return column[0], 1.0
+	}, 0.0, 0.0)
+}
+
+// fitScaler collects each column in sorted order and passes it to the fit
+// function to learn its center and spread.
+func fitScaler(method ScaleMethod, values [][]float64, columns []int, fit func([]float64) (float64, float64), low, high float64) (Scaler, error) {
+	if len(values) == 0 {
+		return Scaler{}, fmt.Errorf("Unable to fit a scaler without data")
+	}
+
+	if len(columns) == 0 {
+		for col := range values[0] {
+			columns = append(columns, col)
+		}
+	}
+
+	result := Scaler{Method: method, Columns: columns, Low: low, High: high}
+	column := make([]float64, len(values))
+	for _, col := range columns {
+		for row := range values {
+			if col < 0 || col >= len(values[row]) {
+				return Scaler{}, fmt.Errorf("Unable to scale column %d", col)
+			}
+			column[row] = values[row][col]
+		}
+		sort.Float64s(column)
+
+		center, spread := fit(column)
+		result.Center = append(result.Center, center)
+		result.Spread = append(result.Spread, spread)
+	}
+	return result, nil
+}
+
+// Transform returns a scaled copy of the values.
+func (s Scaler) Transform(values []float64) ([]float64, error) {
+	return s.apply(values, s.scale)
+}
+
+// Inverse reverses the scaling, returning a copy of the values in their
+// original units.  It is used to turn network outputs trained on scaled
+// expected values back into meaningful values.
+func (s Scaler) Inverse(values []float64) ([]float64, error) {
+	return s.apply(values, s.unscale)
+}
+
+// Validate checks that the scaler's method is known, that it has a center and
+// a spread for each of its columns, that every column is within vectors of the
+// given width and that a min-max scaler's low is less than its high.  It is
+// useful after a scaler has been loaded from somewhere else.
+func (s Scaler) Validate(width int) error {
+	switch s.Method {
+	case MinMaxScaling, ZScoreScaling, RobustScaling, LogScaling:
+	default:
+		return fmt.Errorf("Unknown scaling method %q", s.Method)
+	}
+
+	if len(s.Center) != len(s.Columns) || len(s.Spread) != len(s.Columns) {
+		return fmt.Errorf("Scaler has %d columns but %d centers and %d spreads", len(s.Columns), len(s.Center), len(s.Spread))
+	}
+
+	for _, col := range s.Columns {
+		if col < 0 || col >= width {
+			return fmt.Errorf("Unable to scale column %d of %d values", col, width)
+		}
+	}
+
+	if s.Method == MinMaxScaling && s.Low >= s.High {
+		return fmt.Errorf("Unable to scale between %v and %v, low must be less than high", s.Low, s.High)
+	}
+	return nil
+}
+
+func (s Scaler) apply(values []float64, f func(float64, int) float64) ([]float64, error) {
+	if len(s.Center) != len(s.Columns) || len(s.Spread) != len(s.Columns) {
+		return nil, fmt.Errorf("Scaler has %d columns but %d centers and %d spreads", len(s.Columns), len(s.Center), len(s.Spread))
+	}
+
+	result := make([]float64, len(values))
+	copy(result, values)
+	for idx, col := range s.Columns {
+		if col < 0 || col >= len(values) {
+			return nil, fmt.Errorf("Unable to scale column %d of %d values", col, len(values))
+		}
+		result[col] = f(values[col], idx)
+	}
+	return result, nil
+}
+
+func (s Scaler) spread(idx int) float64 {
+	if s.Spread[idx] == 0.0 {
+		return 1.0
+	}
+	return s.Spread[idx]
+}
+
+func (s Scaler) scale(value float64, idx int) float64 {
+	switch s.Method {
+	case MinMaxScaling:
+		return s.Low + (value-s.Center[idx])/s.spread(idx)*(s.High-s.Low)
+	case LogScaling:
+		return math.Log(value - s.Center[idx] + 1.0)
+	default:
+		return (value - s.Center[idx]) / s.spread(idx)
+	}
+}
+
+func (s Scaler) unscale(value float64, idx int) float64 {
+	switch s.Method {
+	case MinMaxScaling:
+		return (value-s.Low)/(s.High-s.Low)*s.spread(idx) + s.Center[idx]
+	case LogScaling:
+		return math.Exp(value) - 1.0 + s.Center[idx]
+	default:
+		return value*s.spread(idx) + s.Center[idx]
+	}
+}
+
+// TransformInputs returns a copy of the training data with the inputs
+// transformed.  The original training data is not modified.
+func (td TrainingData) TransformInputs(t Transformer) (TrainingData, error) {
+	result := make(TrainingData, len(td))
+	for idx, datum := range td {
+		inputs, err := t.Transform(datum.Inputs)
+		if err != nil {
+			return nil, err
+		}
+		result[idx] = datum
+		result[idx].Inputs = inputs
+	}
+	return result, nil
+}
+
+// TransformExpected returns a copy of the training data with the expected
+// values transformed.  The original training data is not modified.
+func (td TrainingData) TransformExpected(t Transformer) (TrainingData, error) {
+	result := make(TrainingData, len(td))
+	for idx, datum := range td {
+		expected, err := t.Transform(datum.Expected)
+		if err != nil {
+			return nil, err
+		}
+		result[idx] = datum
+		result[idx].Expected = expected
+	}
+	return result, nil
+}
@@ -0,0 +1,197 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+var scalerData = [][]float64{
+	{1.0, 10.0, 5.0},
+	{2.0, 20.0, 5.0},
+	{3.0, 30.0, 5.0},
+	{4.0, 40.0, 5.0},
+	{5.0, 1000.0, 5.0},
+}
+
+func checkRoundTrip(t *testing.T, s Scaler, values []float64) {
+	scaled, err := s.Transform(values)
+	if err != nil {
+		t.Errorf("Failed to transform: %v", err)
+	}
+
+	restored, _ := s.Inverse(scaled)
+	for idx := range values {
+		if outOfBoundsCheck(values[idx], restored[idx], 0.0001) {
+			t.Errorf("%s scaler did not round trip %v, got %v", s.Method, values, restored)
+		}
+	}
+}
+
+func TestFitMinMaxScaler(t *testing.T) {
+	s, err := FitMinMaxScaler(scalerData, 0.1, 0.9, 0, 2)
+	if err != nil {
+		t.Errorf("Failed to fit scaler: %v", err)
+	}
+
+	scaled, _ := s.Transform([]float64{3.0, 30.0, 5.0})
+	if outOfBoundsCheck(0.5, scaled[0], 0.0001) || outOfBoundsCheck(30.0, scaled[1], 0.0001) ||
+		outOfBoundsCheck(0.1, scaled[2], 0.0001) {
+		t.Errorf("Expected [0.5 30.0 0.1] but got %v", scaled)
+	}
+
+	checkRoundTrip(t, s, []float64{7.0, 12.0, 5.0})
+
+	if _, err := FitMinMaxScaler(scalerData, 0.0, 1.0, 3); err == nil {
+		t.Error("Expected an error for a column that does not exist")
+	}
+
+	if _, err := FitMinMaxScaler(scalerData, 0.5, 0.5); err == nil {
+		t.Error("Expected an error when low equals high")
+	}
+
+	if _, err := FitMinMaxScaler(scalerData, 1.0, 0.0); err == nil {
+		t.Error("Expected an error when low is above high")
+	}
+
+	s.Low = s.High
+	if err := s.Validate(3); err == nil {
+		t.Error("Expected a loaded scaler with low equal to high to be invalid")
+	}
+}
+
+func TestFitZScoreScaler(t *testing.T) {
+	s, _ := FitZScoreScaler(scalerData)
+
+	scaled, _ := s.Transform([]float64{3.0, 30.0, 5.0})
+	if outOfBoundsCheck(0.0, scaled[0], 0.0001) || outOfBoundsCheck(0.0, scaled[2], 0.0001) {
+		t.Errorf("Expected the mean to scale to 0.0 but got %v", scaled)
+	}
+
+	high, _ := s.Transform([]float64{3.0 + 1.4142, 0.0, 0.0})
+	if outOfBoundsCheck(1.0, high[0], 0.0001) {
+		t.Errorf("Expected one standard deviation to scale to 1.0 but got %0.4f", high[0])
+	}
+
+	checkRoundTrip(t, s, []float64{-1.0, 50.0, 6.0})
+}
+
+func TestFitRobustScaler(t *testing.T) {
+	s, _ := FitRobustScaler(scalerData, 1)
+
+	scaled, _ := s.Transform([]float64{0.0, 50.0, 0.0})
+	if outOfBoundsCheck(1.0, scaled[1], 0.0001) {
+		t.Errorf("Expected the outlier not to affect scaling but got %0.4f", scaled[1])
+	}
+
+	checkRoundTrip(t, s, []float64{0.0, 25.0, 0.0})
+}
+
+func TestFitLogScaler(t *testing.T) {
+	s, _ := FitLogScaler(scalerData, 1)
+
+	scaled, _ := s.Transform([]float64{0.0, 10.0, 0.0})
+	if outOfBoundsCheck(0.0, scaled[1], 0.0001) {
+		t.Errorf("Expected the minimum to scale to 0.0 but got %0.4f", scaled[1])
+	}
+
+	checkRoundTrip(t, s, []float64{0.0, 500.0, 0.0})
+}
+
+func TestScaler_JSON(t *testing.T) {
+	s, _ := FitMinMaxScaler(scalerData, 0.0, 1.0)
+	data, _ := json.Marshal(s)
+
+	var restored Scaler
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Errorf("Failed to restore scaler: %v", err)
+	}
+
+	scaled, _ := restored.Transform([]float64{5.0, 1000.0, 5.0})
+	if outOfBoundsCheck(1.0, scaled[0], 0.0001) || outOfBoundsCheck(1.0, scaled[1], 0.0001) {
+		t.Errorf("Restored scaler produced %v", scaled)
+	}
+}
+
+func TestScaler_Validate(t *testing.T) {
+	s, _ := FitZScoreScaler(scalerData, 0, 1)
+	if err := s.Validate(3); err != nil {
+		t.Errorf("Expected a valid scaler but got %v", err)
+	}
+
+	if err := s.Validate(1); err == nil {
+		t.Error("Expected an error for a column beyond the width")
+	}
+
+	short := s
+	short.Center = short.Center[:1]
+	if err := short.Validate(3); err == nil {
+		t.Error("Expected an error for a missing center")
+	}
+	if _, err := short.Transform([]float64{1.0, 2.0, 3.0}); err == nil {
+		t.Error("Expected an error transforming with a missing center")
+	}
+
+	negative := s
+	negative.Columns = []int{0, -1}
+	if err := negative.Validate(3); err == nil {
+		t.Error("Expected an error for a negative column")
+	}
+	if _, err := negative.Inverse([]float64{1.0, 2.0, 3.0}); err == nil {
+		t.Error("Expected an error inverting a negative column")
+	}
+
+	unknown := s
+	unknown.Method = ""
+	if err := unknown.Validate(3); err == nil {
+		t.Error("Expected an error for an unknown method")
+	}
+}
+
+func TestTrainingData_TransformInputs(t *testing.T) {
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{1.0}, Expected: []float64{10.0}},
+		TrainingDatum{Inputs: []float64{3.0}, Expected: []float64{30.0}},
+	}
+
+	inputScaler, _ := FitMinMaxScaler(td.InputValues(), 0.0, 1.0)
+	expectedScaler, _ := FitMinMaxScaler(td.ExpectedValues(), 0.1, 0.9)
+
+	scaled, _ := td.TransformInputs(inputScaler)
+	scaled, _ = scaled.TransformExpected(expectedScaler)
+
+	if outOfBoundsCheck(1.0, scaled[1].Inputs[0], 0.0001) || outOfBoundsCheck(0.9, scaled[1].Expected[0], 0.0001) {
+		t.Errorf("Unexpected scaled data %v", scaled)
+	}
+
+	if td[1].Inputs[0] != 3.0 || td[1].Expected[0] != 30.0 {
+		t.Errorf("The original data should not have been modified")
+	}
+}
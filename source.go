@@ -0,0 +1,201 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"io"
+	"math/rand"
+	"os"
+)
+
+// DataSource is a source of training examples that does not need to be held in
+// memory.  Next returns the next example or io.EOF when the source is
+// exhausted and Reset starts the source over from the beginning.  The Trainer
+// resets the source at the start of every iteration.
+type DataSource interface {
+	Next() (TrainingDatum, error)
+	Reset() error
+}
+
+// MemorySource adapts training data that is already in memory to a DataSource.
+type MemorySource struct {
+	Data TrainingData
+	pos  int
+}
+
+// CSVFileSource reads training examples from a CSV file, a chunk of rows at a
+// time, so that only one chunk is in memory at once.  Reset rewinds the file.
+// Close the source when it is no longer needed.
+type CSVFileSource struct {
+	loader    CSVLoader
+	file      *os.File
+	reader    *CSVReader
+	chunk     TrainingData
+	pos       int
+	chunkSize int
+	done      bool
+}
+
+// ShuffleBuffer approximately randomizes the order of another data source.  It
+// holds a buffer of examples and returns a random one from the buffer each
+// time, replacing it with the next example from the underlying source.  A
+// larger buffer gives a more thorough shuffle at the cost of more memory.
+type ShuffleBuffer struct {
+	source DataSource
+	size   int
+	buffer TrainingData
+}
+
+// Source returns a DataSource over the training data.
+func (td TrainingData) Source() *MemorySource {
+	return &MemorySource{Data: td}
+}
+
+// Next returns the next example in the training data.
+func (m *MemorySource) Next() (TrainingDatum, error) {
+	if m.pos >= len(m.Data) {
+		return TrainingDatum{}, io.EOF
+	}
+	m.pos++
+	return m.Data[m.pos-1], nil
+}
+
+// Reset starts over at the first example.
+func (m *MemorySource) Reset() error {
+	m.pos = 0
+	return nil
+}
+
+// OpenFile opens a CSV file as a data source that reads chunkSize rows at a
+// time.
+func (l CSVLoader) OpenFile(path string, chunkSize int) (*CSVFileSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+
+	return &CSVFileSource{loader: l, file: file, reader: l.Open(file), chunkSize: chunkSize}, nil
+}
+
+// Next returns the next example in the file, reading the next chunk of rows
+// when the current chunk is used up.
+func (c *CSVFileSource) Next() (TrainingDatum, error) {
+	if c.pos >= len(c.chunk) {
+		if err := c.readChunk(); err != nil {
+			return TrainingDatum{}, err
+		}
+	}
+	c.pos++
+	return c.chunk[c.pos-1], nil
+}
+
+func (c *CSVFileSource) readChunk() error {
+	if c.done {
+		return io.EOF
+	}
+
+	c.chunk = c.chunk[:0]
+	c.pos = 0
+	for len(c.chunk) < c.chunkSize {
+		datum, err := c.reader.Read()
+		if err == io.EOF {
+			c.done = true
+			break
+		}
+		if err != nil {
+			return err
+		}
+		c.chunk = append(c.chunk, datum)
+	}
+
+	if len(c.chunk) == 0 {
+		return io.EOF
+	}
+	return nil
+}
+
+// Reset rewinds the file to the first row.
+func (c *CSVFileSource) Reset() error {
+	if _, err := c.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	c.reader = c.loader.Open(c.file)
+	c.chunk = c.chunk[:0]
+	c.pos = 0
+	c.done = false
+	return nil
+}
+
+// Close closes the underlying file.
+func (c *CSVFileSource) Close() error {
+	return c.file.Close()
+}
+
+// MakeShuffleBuffer wraps a data source in a shuffle buffer of the given size.
+func MakeShuffleBuffer(source DataSource, size int) *ShuffleBuffer {
+	if size < 1 {
+		size = 1
+	}
+	return &ShuffleBuffer{source: source, size: size}
+}
+
+// Next returns a random example from the buffer, filling the buffer from the
+// underlying source as needed.
+func (s *ShuffleBuffer) Next() (TrainingDatum, error) {
+	for len(s.buffer) < s.size {
+		datum, err := s.source.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return TrainingDatum{}, err
+		}
+		s.buffer = append(s.buffer, datum)
+	}
+
+	if len(s.buffer) == 0 {
+		return TrainingDatum{}, io.EOF
+	}
+
+	idx := rand.Intn(len(s.buffer))
+	result := s.buffer[idx]
+	s.buffer[idx] = s.buffer[len(s.buffer)-1]
+	s.buffer = s.buffer[:len(s.buffer)-1]
+	return result, nil
+}
+
+// Reset empties the buffer and resets the underlying source.
+func (s *ShuffleBuffer) Reset() error {
+	s.buffer = s.buffer[:0]
+	return s.source.Reset()
+}
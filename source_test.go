@@ -0,0 +1,129 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func countSource(t *testing.T, source DataSource) (int, float64) {
+	count, sum := 0, 0.0
+	for {
+		datum, err := source.Next()
+		if err == io.EOF {
+			return count, sum
+		}
+		if err != nil {
+			t.Fatalf("Failed to read source: %v", err)
+		}
+		count++
+		sum += datum.Inputs[0]
+	}
+}
+
+func TestMemorySource(t *testing.T) {
+	source := xorData().Source()
+
+	if count, _ := countSource(t, source); count != 4 {
+		t.Errorf("Expected 4 examples but got %d", count)
+	}
+
+	if count, _ := countSource(t, source); count != 0 {
+		t.Errorf("Expected an exhausted source but got %d examples", count)
+	}
+
+	source.Reset()
+	if count, _ := countSource(t, source); count != 4 {
+		t.Errorf("Expected 4 examples after reset but got %d", count)
+	}
+}
+
+func TestCSVFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	os.WriteFile(path, []byte("x,y\n1,0.1\n2,0.2\n3,0.3\n4,0.4\n5,0.5\n"), 0644)
+
+	loader := CSVLoader{Header: true, Inputs: []Column{ColumnNamed("x")}, Expected: []Column{ColumnNamed("y")}}
+	source, err := loader.OpenFile(path, 2)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer source.Close()
+
+	for round := 0; round < 2; round++ {
+		count, sum := countSource(t, source)
+		if count != 5 || outOfBoundsCheck(15.0, sum, 0.001) {
+			t.Errorf("Expected 5 examples summing to 15 but got %d and %0.1f", count, sum)
+		}
+		source.Reset()
+	}
+}
+
+func TestShuffleBuffer(t *testing.T) {
+	td := TrainingData{}
+	for i := 0; i < 50; i++ {
+		td = append(td, TrainingDatum{Inputs: []float64{float64(i)}, Expected: []float64{0.0}})
+	}
+
+	source := MakeShuffleBuffer(td.Source(), 10)
+	inOrder := true
+	seen := map[float64]bool{}
+	for idx := 0; ; idx++ {
+		datum, err := source.Next()
+		if err == io.EOF {
+			break
+		}
+		seen[datum.Inputs[0]] = true
+		if datum.Inputs[0] != float64(idx) {
+			inOrder = false
+		}
+	}
+
+	if len(seen) != 50 {
+		t.Errorf("Expected all 50 examples but got %d", len(seen))
+	}
+
+	if inOrder {
+		t.Error("The order was not disturbed")
+	}
+}
+
+func TestTrainer_TrainFrom(t *testing.T) {
+	net := MakeNetwork(2, 4, 1)
+	net.Randomize()
+
+	trainer := Trainer{}
+	trainer.AddSimpleStoppingCriteria(50000, 0.001)
+
+	if err := trainer.TrainFrom(&net, MakeShuffleBuffer(xorData().Source(), 4)); err != nil {
+		t.Errorf("Error during training: %v", err)
+	}
+}
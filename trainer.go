@@ -30,6 +30,7 @@ package gofeedforward
 
 import (
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
 )
@@ -45,27 +46,52 @@ type TrainingCallback func(*Trainer)
 // Trainer is a network trainer that trains a network.  The Alpha is the learning rate
 // and has a default of 0.1.  BatchUpdate indicates if updates should occur in a batch or
 // with each presentation.  ShuffleRounds indicates the number of rounds to shuffle the
-// training data before presenting it to the network.
+// training data before presenting it to the network.  OutputWeights, if set, has one
+// weight for each network output and scales that output's share of the weight updates,
+// so a multi-output network can favor its most important outputs.  The weights must not
+// be negative and must not all be zero.  They are normalized to average 1.0, so weights
+// that are all the same train exactly as no weights at all.
 type Trainer struct {
 	endOfIterationHandlers []IterationCallback
 	startTrainingHandlers  []TrainingCallback
 	endTrainingHandlers    []TrainingCallback
 	requestTerminate       bool
+	gradientNorm           float64
 	Alpha                  float64
 	BatchUpdate            bool
 	ShuffleRounds          int
+	OutputWeights          []float64
 }
 
 // TrainingDatum is a training example and is composed of a set of inputs and the
-// expected network outputs.
+// expected network outputs.  Weight scales the example's contribution to the
+// error and to the weight updates during training, so rare examples can count
+// for more.  A Weight of zero means the default, 1.0, so that examples without
+// a weight are trained as usual; it does not exclude the example, which should
+// be left out of the data instead.  Negative weights are rejected.
 type TrainingDatum struct {
 	Inputs   []float64
 	Expected []float64
+	Weight   float64
 }
 
 // TrainingData is a collection of training datum.
 type TrainingData []TrainingDatum
 
+func (d TrainingDatum) weight() float64 {
+	if d.Weight == 0.0 {
+		return 1.0
+	}
+	return d.Weight
+}
+
+func checkWeight(row int, weight float64) error {
+	if weight < 0.0 || isInvalid(weight) {
+		return fmt.Errorf("Example %d has a weight of %v but weights must be zero or more", row, weight)
+	}
+	return nil
+}
+
 // Shuffle is a very crude shuffling algorithm which randomizes the order
 // of the training data.
 func (td TrainingData) Shuffle(rounds int) {
@@ -83,7 +109,9 @@ func (td TrainingData) Shuffle(rounds int) {
 // the minimum value found in the collection of columns and 1.0 is the maximum
 // value.  For example, Scale(0, 2) will scale the contents of the training data
 // inputs in column 0 and column 2, so that the max of column 0 or 2 is 1.0 and
-// the min of either column 0 or 2 is 0.0.
+// the min of either column 0 or 2 is 0.0.  If every value is the same, the
+// columns are set to 0.0.  Scale modifies the data in place and cannot be
+// reapplied to new data; FitMinMaxScaler and the other scalers can.
 func (td TrainingData) Scale(cols ...int) error {
 	for _, col := range cols {
 		if col >= len(td[0].Inputs) || col < 0 {
@@ -106,9 +134,14 @@ func (td TrainingData) Scale(cols ...int) error {
 		}
 	}
 
+	spread := high - low
+	if spread == 0.0 {
+		spread = 1.0
+	}
+
 	for idx := range td {
 		for _, col := range cols {
-			td[idx].Inputs[col] = (td[idx].Inputs[col] - low) / (high - low)
+			td[idx].Inputs[col] = (td[idx].Inputs[col] - low) / spread
 		}
 	}
 
@@ -143,7 +176,9 @@ func calculateDeltas(nextDeltas []float64, layer Layer) []float64 {
 
 func calculateUpdate(layer Layer, deltas []float64, alpha float64) Core {
 	result := MakeCore(layer.Weights.InputSize(), layer.Weights.OutputSize())
-	biasedInputs := append(layer.Inputs, 1.0)
+	// As in Layer.Process, limiting the capacity keeps append from writing into
+	// an example that may be shared with other goroutines.
+	biasedInputs := append(layer.Inputs[:len(layer.Inputs):len(layer.Inputs)], 1.0)
 	for row := range layer.Weights {
 		for col := range layer.Weights[row] {
 			result[row][col] = biasedInputs[col] * deltas[row] * -alpha
@@ -153,37 +188,96 @@ func calculateUpdate(layer Layer, deltas []float64, alpha float64) Core {
 }
 
 // OneIteration conducts a training iteration.  It takes  a network and some training data and
-// returns the mean squared error array for all the network outputs.
+// returns the mean squared error array for all the network outputs.  When examples are
+// weighted, the error is the weighted mean.
 func (t Trainer) OneIteration(net *Network, data TrainingData) (SquaredError, error) {
+	if t.ShuffleRounds > 0 {
+		data.Shuffle(t.ShuffleRounds)
+	}
+
+	return t.OneIterationFrom(net, data.Source())
+}
+
+// OneIterationFrom conducts a training iteration over every example in a data
+// source.  The source is reset before the iteration starts.  ShuffleRounds does
+// not apply to a data source; wrap the source in a ShuffleBuffer instead.  An
+// example with a NaN or infinite value stops the iteration with an
+// InvalidValueError before it can affect the weights, as does an example with
+// a negative weight.
+func (t Trainer) OneIterationFrom(net *Network, source DataSource) (SquaredError, error) {
+	mse, _, err := t.oneIteration(net, source)
+	return mse, err
+}
+
+// oneIteration conducts a training iteration and also returns the norm of the
+// gradient of the mean error with respect to all of the weights.
+func (t Trainer) oneIteration(net *Network, source DataSource) (SquaredError, float64, error) {
 	deltas := [][]float64{}
-	updates := []Core{}
+	gradients := []Core{}
 	for _, layer := range net.Layers {
 		deltas = append(deltas, make([]float64, layer.Weights.OutputSize()))
-		updates = append(updates, MakeCore(layer.Weights.InputSize(), layer.Weights.OutputSize()))
+		gradients = append(gradients, MakeCore(layer.Weights.InputSize(), layer.Weights.OutputSize()))
 	}
 
-	if t.ShuffleRounds > 0 {
-		data.Shuffle(t.ShuffleRounds)
+	outputWeights, err := t.outputWeights(net.OutputSize())
+	if err != nil {
+		return nil, 0.0, err
+	}
+
+	if err := source.Reset(); err != nil {
+		return nil, 0.0, err
 	}
 
 	total := SquaredError(make([]float64, net.OutputSize()))
+	count := 0
+	totalWeight := 0.0
+
+	for {
+		datum, err := source.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0.0, err
+		}
+		count++
+
+		if err := checkValues(count-1, datum.Inputs, false); err != nil {
+			return nil, 0.0, err
+		}
+
+		if err := checkValues(count-1, datum.Expected, true); err != nil {
+			return nil, 0.0, err
+		}
+
+		if err := checkWeight(count-1, datum.Weight); err != nil {
+			return nil, 0.0, err
+		}
 
-	for _, datum := range data {
 		outputs, err := net.Process(datum.Inputs)
 		if err != nil {
-			return nil, err
+			return nil, 0.0, err
 		}
 
 		if len(datum.Expected) != len(outputs) {
-			return nil, fmt.Errorf("Failed to processes data with length %d against expected output of length %d",
+			return nil, 0.0, fmt.Errorf("Failed to processes data with length %d against expected output of length %d",
 				len(datum.Expected), len(outputs))
 		}
 
+		weight := datum.weight()
+		totalWeight += weight
+
 		sse, _ := CalcError(datum.Expected, outputs)
+		for i := range sse {
+			sse[i] *= weight
+		}
 		total.Accumulate(sse)
 
 		for i := 0; i < len(datum.Expected); i++ {
-			deltas[len(net.Layers)-1][i] = (outputs[i] - datum.Expected[i]) * outputs[i] * (1 - outputs[i])
+			deltas[len(net.Layers)-1][i] = (outputs[i] - datum.Expected[i]) * outputs[i] * (1 - outputs[i]) * weight
+			if outputWeights != nil {
+				deltas[len(net.Layers)-1][i] *= outputWeights[i]
+			}
 		}
 
 		for i := len(net.Layers) - 2; i >= 0; i-- {
@@ -191,27 +285,37 @@ func (t Trainer) OneIteration(net *Network, data TrainingData) (SquaredError, er
 		}
 
 		for i := 0; i < len(net.Layers); i++ {
-			update := calculateUpdate(net.Layers[i], deltas[i], t.Alpha)
+			gradient := calculateUpdate(net.Layers[i], deltas[i], -1.0)
 
 			if !t.BatchUpdate {
-				net.Layers[i].UpdateWeights(update)
-			} else {
-				update, err = updates[i].Add(update)
-				updates[i] = update
+				net.Layers[i].UpdateWeights(gradient.Scale(-t.Alpha))
 			}
+
+			gradients[i], err = gradients[i].Add(gradient)
 			if err != nil {
-				return nil, err
+				return nil, 0.0, err
 			}
 		}
 	}
 
 	if t.BatchUpdate {
 		for idx := range net.Layers {
-			net.Layers[idx].UpdateWeights(updates[idx])
+			net.Layers[idx].UpdateWeights(gradients[idx].Scale(-t.Alpha))
 		}
 	}
-	total.Average(len(data))
-	return total, nil
+	for i := range total {
+		total[i] = total[i] / totalWeight
+	}
+
+	norm := 0.0
+	for _, gradient := range gradients {
+		for _, row := range gradient {
+			for _, val := range row {
+				norm += val * val
+			}
+		}
+	}
+	return total, math.Sqrt(norm) / totalWeight, nil
 }
 
 // AddIterationEndHandler adds an end of iteration callback function.
@@ -229,22 +333,78 @@ func (t *Trainer) AddTrainingEndHandler(handler TrainingCallback) {
 	t.endTrainingHandlers = append(t.endTrainingHandlers, handler)
 }
 
+// GradientNorm returns the norm of the gradient of the mean error with respect to every
+// weight in the network, as calculated in the last training iteration.  A gradient norm
+// that shrinks towards zero indicates training is converging; one that grows very large
+// indicates the learning rate may be too high.
+func (t *Trainer) GradientNorm() float64 {
+	return t.gradientNorm
+}
+
 // RequestTermination is called to break the training loop
 func (t *Trainer) RequestTermination() {
 	t.requestTerminate = true
 }
 
+// outputWeights checks the OutputWeights against the number of network outputs and
+// normalizes them to average 1.0.  It returns nil if there are no OutputWeights.
+func (t Trainer) outputWeights(outputs int) ([]float64, error) {
+	if t.OutputWeights == nil {
+		return nil, nil
+	}
+
+	if len(t.OutputWeights) != outputs {
+		return nil, fmt.Errorf("Trainer has %d output weights but the network has %d outputs",
+			len(t.OutputWeights), outputs)
+	}
+
+	total := 0.0
+	for idx, weight := range t.OutputWeights {
+		if weight < 0.0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("Output weight %d is %v but must be zero or more", idx, weight)
+		}
+		total += weight
+	}
+
+	if total <= 0.0 {
+		return nil, fmt.Errorf("Output weights must not all be zero")
+	}
+
+	result := make([]float64, len(t.OutputWeights))
+	for idx, weight := range t.OutputWeights {
+		result[idx] = weight * float64(outputs) / total
+	}
+	return result, nil
+}
+
+// Loss combines the mean squared error for each output into the single value the trainer
+// is minimizing.  Without OutputWeights it is the same as Combine; with them each output's
+// error is scaled by its normalized weight, exactly as in the weight updates.  If the
+// weights are not valid for the outputs it falls back to Combine.
+func (t Trainer) Loss(mse SquaredError) float64 {
+	weights, err := t.outputWeights(len(mse))
+	if err != nil || weights == nil {
+		return mse.Combine()
+	}
+
+	loss := 0.0
+	for idx := range mse {
+		loss += weights[idx] * mse[idx]
+	}
+	return loss
+}
+
 // AddSimpleStoppingCriteria registers an end of iteration callback that will check to see
-// if either the maximum iterations have been exceeded or the mean squared error is less
-// than the minimum error.  If either of these conditions are met, then the callback
-// requests termination.
+// if either the maximum iterations have been exceeded or the loss, the mean squared error
+// weighted by any OutputWeights, is less than the minimum error.  If either of these
+// conditions are met, then the callback requests termination.
 func (t *Trainer) AddSimpleStoppingCriteria(maxIter int, minErr float64) {
 	t.AddIterationEndHandler(func(t *Trainer, mse SquaredError, iter int, err error) {
 		if iter > maxIter {
 			t.RequestTermination()
 		}
 
-		if mse.Combine() < minErr {
+		if t.Loss(mse) < minErr {
 			t.RequestTermination()
 		}
 	})
@@ -255,6 +415,23 @@ func (t *Trainer) AddSimpleStoppingCriteria(maxIter int, minErr float64) {
 // requested.  At the end of each iteration in calls the end of iteration callbacks.  When
 // training is finished, it calls the end of training callbacks.
 func (t *Trainer) Train(net *Network, td TrainingData) (err error) {
+	return t.train(func() (SquaredError, float64, error) {
+		if t.ShuffleRounds > 0 {
+			td.Shuffle(t.ShuffleRounds)
+		}
+		return t.oneIteration(net, td.Source())
+	})
+}
+
+// TrainFrom conducts the training loop in the same way as Train, but reads the
+// examples for each iteration from a data source instead of from memory.
+func (t *Trainer) TrainFrom(net *Network, source DataSource) error {
+	return t.train(func() (SquaredError, float64, error) {
+		return t.oneIteration(net, source)
+	})
+}
+
+func (t *Trainer) train(iterate func() (SquaredError, float64, error)) (err error) {
 	if t.Alpha == 0.0 {
 		t.Alpha = 0.1
 	}
@@ -268,7 +445,8 @@ func (t *Trainer) Train(net *Network, td TrainingData) (err error) {
 	iteration := 0
 	for {
 		iteration++
-		mse, err := t.OneIteration(net, td)
+		mse, norm, err := iterate()
+		t.gradientNorm = norm
 
 		if len(t.endOfIterationHandlers) > 0 {
 			for _, eoi := range t.endOfIterationHandlers {
@@ -70,6 +70,45 @@ func TestTrainer_OneIteration(t *testing.T) {
 	}
 }
 
+func TestTrainer_OneIterationWeights(t *testing.T) {
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{1.0, 0.0}, Expected: []float64{1.0, 0.0}},
+		TrainingDatum{Inputs: []float64{0.0, 1.0}, Expected: []float64{0.0, 1.0}},
+	}
+
+	expected := map[bool][]Core{
+		false: {
+			{{0.093797, -0.202625, 0.291172}, {0.406390, 0.502831, -0.590779}},
+			{{0.678526, -0.822189, 0.854985}, {-0.113878, 0.194085, 0.283662}},
+		},
+		true: {
+			{{0.093797, -0.203406, 0.290392}, {0.406390, 0.503406, -0.590204}},
+			{{0.678203, -0.822264, 0.854584}, {-0.115911, 0.192082, 0.279629}},
+		},
+	}
+
+	for batch, layers := range expected {
+		net := MakeNetwork(2, 2, 2)
+		net.Layers[0].Weights = Core{{0.1, -0.2, 0.3}, {0.4, 0.5, -0.6}}
+		net.Layers[1].Weights = Core{{0.7, -0.8, 0.9}, {-0.1, 0.2, 0.3}}
+
+		trainer := Trainer{Alpha: 0.5, BatchUpdate: batch}
+		if _, err := trainer.OneIteration(&net, td); err != nil {
+			t.Fatalf("Failed to train network: %v", err)
+		}
+
+		for idx, weights := range layers {
+			for row := range weights {
+				for col := range weights[row] {
+					if outOfBoundsCheck(weights[row][col], net.Layers[idx].Weights[row][col], 0.000001) {
+						t.Errorf("Batch %v: expected layer %d weights %v but got %v", batch, idx, weights, net.Layers[idx].Weights)
+					}
+				}
+			}
+		}
+	}
+}
+
 func TestTrainer_Train(t *testing.T) {
 	td := xorData()
 
@@ -200,3 +239,120 @@ func TestTrainingData_Scale(t *testing.T) {
 		t.Errorf("Expected high value to be 1.0, but got %0.4f", td[0].Inputs[2])
 	}
 }
+
+func TestTrainingData_ScaleConstant(t *testing.T) {
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{3.0}, Expected: []float64{1.0}},
+		TrainingDatum{Inputs: []float64{3.0}, Expected: []float64{2.0}},
+	}
+
+	td.Scale(0)
+	if outOfBoundsCheck(0.0, td[0].Inputs[0], 0.001) || outOfBoundsCheck(0.0, td[1].Inputs[0], 0.001) {
+		t.Errorf("Expected a constant column to scale to 0.0 but got %v", td)
+	}
+}
+
+func TestTrainer_OutputWeights(t *testing.T) {
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{1.0}, Expected: []float64{0.9, 0.9}},
+	}
+
+	trainer := Trainer{Alpha: 0.5, OutputWeights: []float64{1.0, 0.0}}
+	net := MakeNetwork(1, 2)
+	if _, err := trainer.OneIteration(&net, td); err != nil {
+		t.Errorf("Failed to train: %v", err)
+	}
+
+	if !outOfBoundsCheck(0.0, net.Layers[0].Weights[0][0], 0.0001) {
+		t.Errorf("Expected the first output to be trained")
+	}
+
+	if outOfBoundsCheck(0.0, net.Layers[0].Weights[1][0], 0.0001) {
+		t.Errorf("Expected the output with no weight to be left alone but got %v", net.Layers[0].Weights[1])
+	}
+
+	for _, weights := range [][]float64{{1.0}, {0.0, 0.0}, {2.0, -1.0}} {
+		trainer.OutputWeights = weights
+		if _, err := trainer.OneIteration(&net, td); err == nil {
+			t.Errorf("Expected an error for the output weights %v", weights)
+		}
+	}
+}
+
+func TestTrainer_EqualOutputWeights(t *testing.T) {
+	td := TrainingData{
+		TrainingDatum{Inputs: []float64{1.0, 0.0}, Expected: []float64{0.9, 0.1}},
+		TrainingDatum{Inputs: []float64{0.0, 1.0}, Expected: []float64{0.1, 0.9}},
+	}
+
+	train := func(weights []float64) (Network, float64) {
+		net := MakeNetwork(2, 2, 2)
+		net.Layers[0].Weights = Core{{0.1, -0.2, 0.3}, {0.4, 0.5, -0.6}}
+		net.Layers[1].Weights = Core{{0.7, -0.8, 0.9}, {-0.1, 0.2, 0.3}}
+
+		trainer := Trainer{OutputWeights: weights}
+		trainer.AddSimpleStoppingCriteria(20, 0.0)
+		var loss float64
+		trainer.AddIterationEndHandler(func(t *Trainer, mse SquaredError, iter int, err error) {
+			loss = t.Loss(mse)
+		})
+
+		if err := trainer.Train(&net, td); err != nil {
+			t.Fatalf("Failed to train: %v", err)
+		}
+		return net, loss
+	}
+
+	unweighted, unweightedLoss := train(nil)
+	weighted, weightedLoss := train([]float64{1.0, 1.0})
+	if weightedLoss != unweightedLoss {
+		t.Errorf("Expected the same loss but got %v and %v", unweightedLoss, weightedLoss)
+	}
+
+	for idx := range unweighted.Layers {
+		for row := range unweighted.Layers[idx].Weights {
+			for col, value := range unweighted.Layers[idx].Weights[row] {
+				if weighted.Layers[idx].Weights[row][col] != value {
+					t.Errorf("Expected the same weights but got %v and %v", unweighted.Layers[idx].Weights, weighted.Layers[idx].Weights)
+				}
+			}
+		}
+	}
+}
+
+func TestTrainer_Loss(t *testing.T) {
+	mse := SquaredError{0.2, 0.4}
+
+	if outOfBoundsCheck(0.6, (Trainer{}).Loss(mse), 0.0001) {
+		t.Errorf("Expected an unweighted loss of 0.6 but got %0.4f", (Trainer{}).Loss(mse))
+	}
+
+	trainer := Trainer{OutputWeights: []float64{2.0, 0.5}}
+	if outOfBoundsCheck(0.48, trainer.Loss(mse), 0.0001) {
+		t.Errorf("Expected the weighted loss 0.48 but got %0.4f", trainer.Loss(mse))
+	}
+
+	trainer.OutputWeights = []float64{3.0, 3.0}
+	if trainer.Loss(mse) != (Trainer{}).Loss(mse) {
+		t.Errorf("Expected equal weights to give the unweighted loss but got %0.4f", trainer.Loss(mse))
+	}
+
+	for _, weights := range [][]float64{{0.0, 0.0}, {-1.0, 1.0}} {
+		trainer.OutputWeights = weights
+		if outOfBoundsCheck(0.6, trainer.Loss(mse), 0.0001) {
+			t.Errorf("Expected weights %v to fall back to 0.6 but got %0.4f", weights, trainer.Loss(mse))
+		}
+	}
+
+	trainer.OutputWeights = []float64{1.0}
+	if outOfBoundsCheck(0.6, trainer.Loss(mse), 0.0001) {
+		t.Errorf("Expected mismatched weights to fall back to 0.6 but got %0.4f", trainer.Loss(mse))
+	}
+
+	trainer.OutputWeights = []float64{1.0, 0.0}
+	trainer.AddSimpleStoppingCriteria(100, 0.5)
+	trainer.endOfIterationHandlers[0](&trainer, mse, 1, nil)
+	if !trainer.requestTerminate {
+		t.Errorf("Expected the weighted loss of 0.4 to stop training")
+	}
+}
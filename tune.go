@@ -0,0 +1,496 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// SearchSpace declares the settings a Tuner tries.  Hidden lists the hidden
+// layer sizes to try, for example {{4}, {8}, {8, 4}}, and an empty list tries
+// only a network with no hidden layers.  Activations may only hold "sigmoid",
+// the one activation the network supports, and defaults to it.  Alphas
+// defaults to the Trainer's default of 0.1 and BatchUpdates to false.
+type SearchSpace struct {
+	Hidden       [][]int
+	Activations  []string
+	Alphas       []float64
+	BatchUpdates []bool
+}
+
+// Trial is one combination of settings from a search space.
+type Trial struct {
+	Hidden      []int
+	Activation  string
+	Alpha       float64
+	BatchUpdate bool
+}
+
+// TrialResult is the outcome of a trial.  Loss is the validation loss averaged
+// over the folds and Spread is its standard deviation.  Iterations is the
+// most training iterations each fold's network was given and Seconds is the
+// time spent training and validating.  Err is set if the trial failed.
+type TrialResult struct {
+	Trial
+	Loss       float64
+	Spread     float64
+	Iterations int
+	Seconds    float64
+	Err        error
+}
+
+// Leaderboard is the results of a search, ranked from best to worst.  Trials
+// that were given more iterations rank ahead of those that were not, then
+// trials rank by their loss, and failed trials come last.
+type Leaderboard []TrialResult
+
+// Tuner searches a space of network topologies and Trainer settings for the
+// combination with the lowest validation loss.  If Folds is greater than one,
+// each trial is cross validated with that many folds; otherwise Split is the
+// fraction of the data used for training, defaulting to 0.8, and the rest is
+// used for validation.  Each trial trains for MaxIterations, defaulting to
+// 100, or until its loss is less than MinError.  Parallel is the number of
+// trials run at the same time and defaults to the number of CPUs.  The loss is
+// calculated as the Trainer does, with any OutputWeights.
+type Tuner struct {
+	Space         SearchSpace
+	Folds         int
+	Split         float64
+	MaxIterations int
+	MinError      float64
+	Parallel      int
+	OutputWeights []float64
+}
+
+// run is a trial in progress with a network for each fold, so that successive
+// halving can continue training the trials that survive each round.
+type run struct {
+	nets   []Network
+	result TrialResult
+}
+
+func (s SearchSpace) withDefaults() (SearchSpace, error) {
+	if len(s.Hidden) == 0 {
+		s.Hidden = [][]int{nil}
+	}
+
+	if len(s.Activations) == 0 {
+		s.Activations = []string{"sigmoid"}
+	}
+
+	for _, activation := range s.Activations {
+		if activation != "sigmoid" {
+			return s, fmt.Errorf("Unsupported activation %q, only sigmoid is available", activation)
+		}
+	}
+
+	if len(s.Alphas) == 0 {
+		s.Alphas = []float64{0.1}
+	}
+
+	if len(s.BatchUpdates) == 0 {
+		s.BatchUpdates = []bool{false}
+	}
+	return s, nil
+}
+
+// Grid returns every combination of settings in the search space.
+func (s SearchSpace) Grid() ([]Trial, error) {
+	s, err := s.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	trials := []Trial{}
+	for _, hidden := range s.Hidden {
+		for _, activation := range s.Activations {
+			for _, alpha := range s.Alphas {
+				for _, batch := range s.BatchUpdates {
+					trials = append(trials, Trial{Hidden: hidden, Activation: activation, Alpha: alpha, BatchUpdate: batch})
+				}
+			}
+		}
+	}
+	return trials, nil
+}
+
+// Sample returns the given number of trials with each setting chosen at random
+// from the search space.  The same combination may be chosen more than once.
+func (s SearchSpace) Sample(count int) ([]Trial, error) {
+	s, err := s.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	trials := make([]Trial, count)
+	for idx := range trials {
+		trials[idx] = Trial{
+			Hidden:      s.Hidden[rand.Intn(len(s.Hidden))],
+			Activation:  s.Activations[rand.Intn(len(s.Activations))],
+			Alpha:       s.Alphas[rand.Intn(len(s.Alphas))],
+			BatchUpdate: s.BatchUpdates[rand.Intn(len(s.BatchUpdates))],
+		}
+	}
+	return trials, nil
+}
+
+// Network returns a randomized network with the trial's hidden layers between
+// the given number of inputs and outputs.
+func (t Trial) Network(inputs, outputs int) Network {
+	sizes := append(append([]int{inputs}, t.Hidden...), outputs)
+	net := MakeNetwork(sizes...)
+	net.Randomize()
+	return net
+}
+
+// Trainer returns a trainer with the trial's settings.
+func (t Trial) Trainer() Trainer {
+	return Trainer{Alpha: t.Alpha, BatchUpdate: t.BatchUpdate}
+}
+
+func (t Trial) String() string {
+	return fmt.Sprintf("hidden %v %s alpha %v batch %v", t.Hidden, t.Activation, t.Alpha, t.BatchUpdate)
+}
+
+// GridSearch runs a trial for every combination in the search space.
+func (t Tuner) GridSearch(data TrainingData) (Leaderboard, error) {
+	trials, err := t.Space.Grid()
+	if err != nil {
+		return nil, err
+	}
+	return t.Search(data, trials)
+}
+
+// RandomSearch runs the given number of trials chosen at random from the
+// search space.
+func (t Tuner) RandomSearch(data TrainingData, count int) (Leaderboard, error) {
+	trials, err := t.Space.Sample(count)
+	if err != nil {
+		return nil, err
+	}
+	return t.Search(data, trials)
+}
+
+// Search runs each of the trials for MaxIterations and ranks them.
+func (t Tuner) Search(data TrainingData, trials []Trial) (Leaderboard, error) {
+	training, validation, err := t.folds(data)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := t.start(trials, data)
+	t.advance(runs, training, validation, t.maxIterations())
+	return leaderboard(runs), nil
+}
+
+// SuccessiveHalving runs the given number of trials chosen at random from the
+// search space with a small number of iterations, keeps the best 1/eta of them
+// and continues training those with eta times as many iterations, until the
+// last round, which trains the survivors to MaxIterations.  It finds good
+// settings for much less training than a full search, although a trial that
+// starts slowly may be dropped early.  The leaderboard ranks the trials that
+// survived the longest first.
+func (t Tuner) SuccessiveHalving(data TrainingData, count, eta int) (Leaderboard, error) {
+	if eta < 2 {
+		return nil, fmt.Errorf("Successive halving needs an eta of at least 2, not: %d", eta)
+	}
+
+	trials, err := t.Space.Sample(count)
+	if err != nil {
+		return nil, err
+	}
+
+	training, validation, err := t.folds(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rounds := 0
+	for n := count; n >= eta; n /= eta {
+		rounds++
+	}
+
+	runs := t.start(trials, data)
+	active := runs
+	for round := 0; round <= rounds; round++ {
+		iterations := t.maxIterations()
+		for i := round; i < rounds; i++ {
+			iterations /= eta
+		}
+
+		t.advance(active, training, validation, max(iterations, 1))
+		if round == rounds {
+			break
+		}
+
+		active = leaderboardOrder(active)
+		keep := max(len(active)/eta, 1)
+		active = active[:keep]
+	}
+	return leaderboard(runs), nil
+}
+
+func (t Tuner) maxIterations() int {
+	if t.MaxIterations <= 0 {
+		return 100
+	}
+	return t.MaxIterations
+}
+
+// folds shuffles a copy of the data and divides it into the training and
+// validation data for each fold.
+func (t Tuner) folds(data TrainingData) ([]TrainingData, []TrainingData, error) {
+	shuffled := append(TrainingData{}, data...)
+	shuffled.Shuffle(1)
+
+	if t.Folds <= 1 {
+		split := t.Split
+		if split == 0.0 {
+			split = 0.8
+		}
+
+		training, validation, err := shuffled.Split(split)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(training) == 0 || len(validation) == 0 {
+			return nil, nil, fmt.Errorf("Splitting %d examples at %0.4f leaves no training or validation data", len(data), split)
+		}
+		return []TrainingData{training}, []TrainingData{validation}, nil
+	}
+
+	if t.Folds > len(data) {
+		return nil, nil, fmt.Errorf("Unable to make %d folds from %d examples", t.Folds, len(data))
+	}
+
+	training := make([]TrainingData, t.Folds)
+	validation := make([]TrainingData, t.Folds)
+	for fold := 0; fold < t.Folds; fold++ {
+		start, end := fold*len(shuffled)/t.Folds, (fold+1)*len(shuffled)/t.Folds
+		validation[fold] = shuffled[start:end]
+		training[fold] = append(append(TrainingData{}, shuffled[:start]...), shuffled[end:]...)
+	}
+	return training, validation, nil
+}
+
+// start creates a run, with a network for each fold, for each trial.
+func (t Tuner) start(trials []Trial, data TrainingData) []*run {
+	folds := max(t.Folds, 1)
+	runs := make([]*run, len(trials))
+	for idx, trial := range trials {
+		runs[idx] = &run{result: TrialResult{Trial: trial}}
+		if len(data) == 0 {
+			runs[idx].result.Err = fmt.Errorf("No training data")
+			continue
+		}
+
+		for fold := 0; fold < folds; fold++ {
+			runs[idx].nets = append(runs[idx].nets, trial.Network(len(data[0].Inputs), len(data[0].Expected)))
+		}
+	}
+	return runs
+}
+
+// advance trains the runs, in parallel, until they have had the given number
+// of iterations and then measures their validation loss.
+func (t Tuner) advance(runs []*run, training, validation []TrainingData, iterations int) {
+	workers := t.Parallel
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan *run)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				t.train(r, training, validation, iterations)
+			}
+		}()
+	}
+
+	for _, r := range runs {
+		if r.result.Err == nil {
+			jobs <- r
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// train trains each fold's network of a run for the iterations it has not yet
+// had.  Each fold gets its own copy of the training data because the trainer
+// shuffles it in place.
+func (t Tuner) train(r *run, training, validation []TrainingData, iterations int) {
+	start := time.Now()
+	remaining := iterations - r.result.Iterations
+	losses := make([]float64, len(r.nets))
+	for fold := range r.nets {
+		trainer := r.result.Trial.Trainer()
+		trainer.ShuffleRounds = 1
+		trainer.OutputWeights = t.OutputWeights
+		trainer.AddIterationEndHandler(func(tr *Trainer, mse SquaredError, iter int, err error) {
+			if iter >= remaining || tr.Loss(mse) < t.MinError {
+				tr.RequestTermination()
+			}
+		})
+
+		if remaining > 0 {
+			if err := trainer.Train(&r.nets[fold], append(TrainingData{}, training[fold]...)); err != nil {
+				r.result.Err = err
+				return
+			}
+		}
+
+		allErrors, err := Evaluate(r.nets[fold], validation[fold])
+		if err != nil {
+			r.result.Err = err
+			return
+		}
+		losses[fold] = trainer.Loss(allErrors.Average())
+	}
+
+	mean, spread := 0.0, 0.0
+	for _, loss := range losses {
+		mean += loss
+	}
+	mean /= float64(len(losses))
+
+	for _, loss := range losses {
+		spread += (loss - mean) * (loss - mean)
+	}
+
+	r.result.Loss = mean
+	r.result.Spread = math.Sqrt(spread / float64(len(losses)))
+	r.result.Iterations = max(iterations, r.result.Iterations)
+	r.result.Seconds += time.Since(start).Seconds()
+}
+
+// leaderboardOrder sorts the runs in leaderboard order.
+func leaderboardOrder(runs []*run) []*run {
+	sorted := append([]*run{}, runs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		left, right := sorted[i].result, sorted[j].result
+		if (left.Err == nil) != (right.Err == nil) {
+			return left.Err == nil
+		}
+
+		if left.Iterations != right.Iterations {
+			return left.Iterations > right.Iterations
+		}
+		return left.Loss < right.Loss
+	})
+	return sorted
+}
+
+func leaderboard(runs []*run) Leaderboard {
+	result := Leaderboard{}
+	for _, r := range leaderboardOrder(runs) {
+		result = append(result, r.result)
+	}
+	return result
+}
+
+// Best returns the best trial that did not fail.
+func (l Leaderboard) Best() (TrialResult, error) {
+	if len(l) == 0 || l[0].Err != nil {
+		return TrialResult{}, fmt.Errorf("No trial succeeded")
+	}
+	return l[0], nil
+}
+
+func (l Leaderboard) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(w, "rank\thidden\tactivation\talpha\tbatch\tloss\tspread\titerations\tseconds\t")
+	for idx, result := range l {
+		fmt.Fprintf(w, "%d\t%v\t%s\t%v\t%v\t", idx+1, result.Hidden, result.Activation, result.Alpha, result.BatchUpdate)
+		if result.Err != nil {
+			fmt.Fprintf(w, "failed: %v\t\t\t\t\n", result.Err)
+			continue
+		}
+		fmt.Fprintf(w, "%0.6f\t%0.6f\t%d\t%0.2f\t\n", result.Loss, result.Spread, result.Iterations, result.Seconds)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// WriteCSV writes one row per trial, in rank order, with a header row.  The
+// hidden layer sizes are separated by spaces and the error column is empty
+// for trials that succeeded.
+func (l Leaderboard) WriteCSV(out io.Writer) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"rank", "hidden", "activation", "alpha", "batch", "loss", "spread", "iterations", "seconds", "error"})
+
+	for idx, result := range l {
+		hidden := ""
+		for i, size := range result.Hidden {
+			if i > 0 {
+				hidden += " "
+			}
+			hidden += strconv.Itoa(size)
+		}
+
+		errText := ""
+		if result.Err != nil {
+			errText = result.Err.Error()
+		}
+
+		w.Write([]string{
+			strconv.Itoa(idx + 1),
+			hidden,
+			result.Activation,
+			strconv.FormatFloat(result.Alpha, 'g', -1, 64),
+			strconv.FormatBool(result.BatchUpdate),
+			strconv.FormatFloat(result.Loss, 'g', -1, 64),
+			strconv.FormatFloat(result.Spread, 'g', -1, 64),
+			strconv.Itoa(result.Iterations),
+			strconv.FormatFloat(result.Seconds, 'g', -1, 64),
+			errText,
+		})
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,202 @@
+/*
+BSD 2-Clause License
+
+Copyright (c) 2016, Darc Inc
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package gofeedforward
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var errTest = errors.New("test failure")
+
+func stepData() TrainingData {
+	data := TrainingData{}
+	for i := 0; i < 20; i++ {
+		x := float64(i) / 20.0
+		expected := 0.1
+		if x > 0.5 {
+			expected = 0.9
+		}
+		data = append(data, TrainingDatum{Inputs: []float64{x}, Expected: []float64{expected}})
+	}
+	return data
+}
+
+func TestSearchSpace_Grid(t *testing.T) {
+	space := SearchSpace{Hidden: [][]int{{2}, {4, 2}}, Alphas: []float64{0.1, 0.5}, BatchUpdates: []bool{false, true}}
+	trials, err := space.Grid()
+	if err != nil {
+		t.Fatalf("Failed to make grid: %v", err)
+	}
+
+	if len(trials) != 8 || trials[0].Activation != "sigmoid" {
+		t.Errorf("Expected 8 sigmoid trials but got %v", trials)
+	}
+
+	trials, err = SearchSpace{}.Sample(3)
+	if err != nil || len(trials) != 3 || trials[2].Alpha != 0.1 || trials[2].Hidden != nil {
+		t.Errorf("Expected 3 default trials but got %v %v", trials, err)
+	}
+
+	if _, err := (SearchSpace{Activations: []string{"relu"}}).Grid(); err == nil {
+		t.Errorf("Expected an error for an unsupported activation")
+	}
+}
+
+func TestTuner_GridSearch(t *testing.T) {
+	tuner := Tuner{
+		Space:         SearchSpace{Hidden: [][]int{{}, {2}}, Alphas: []float64{0.5, 0.001}},
+		MaxIterations: 50,
+		Parallel:      2,
+	}
+
+	board, err := tuner.GridSearch(stepData())
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+
+	if len(board) != 4 {
+		t.Fatalf("Expected 4 results but got %d", len(board))
+	}
+
+	for idx, result := range board {
+		if result.Err != nil || result.Iterations != 50 {
+			t.Errorf("Unexpected result %v", result)
+		}
+
+		if idx > 0 && result.Loss < board[idx-1].Loss {
+			t.Errorf("Expected results ranked by loss but got %v", board)
+		}
+	}
+
+	best, err := board.Best()
+	if err != nil || best.Alpha != 0.5 {
+		t.Errorf("Expected the higher learning rate to win but got %v %v", best, err)
+	}
+}
+
+func TestTuner_SharedExamples(t *testing.T) {
+	data := stepData()
+	for idx := range data {
+		inputs := make([]float64, 1, 8)
+		inputs[0] = data[idx].Inputs[0]
+		for spare := 1; spare < cap(inputs); spare++ {
+			inputs[:cap(inputs)][spare] = -1.0
+		}
+		data[idx].Inputs = inputs
+	}
+
+	tuner := Tuner{Space: SearchSpace{Hidden: [][]int{{}, {2}}, Alphas: []float64{0.5, 0.1, 0.01}}, MaxIterations: 20, Parallel: 3}
+	if _, err := tuner.GridSearch(data); err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+
+	for _, datum := range data {
+		for _, value := range datum.Inputs[1:cap(datum.Inputs)] {
+			if value != -1.0 {
+				t.Fatalf("Expected the spare capacity of the inputs to be untouched but got %v", datum.Inputs[:cap(datum.Inputs)])
+			}
+		}
+	}
+}
+
+func TestTuner_Folds(t *testing.T) {
+	tuner := Tuner{Folds: 4, MaxIterations: 5}
+	board, err := tuner.RandomSearch(stepData(), 2)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+
+	if len(board) != 2 || board[0].Err != nil || board[0].Spread == 0.0 {
+		t.Errorf("Expected cross validated results but got %v", board)
+	}
+
+	tuner.Folds = 21
+	if _, err := tuner.RandomSearch(stepData(), 1); err == nil {
+		t.Errorf("Expected an error for more folds than examples")
+	}
+
+	tuner = Tuner{Split: 1.0}
+	if _, err := tuner.RandomSearch(stepData(), 1); err == nil {
+		t.Errorf("Expected an error for a split without validation data")
+	}
+}
+
+func TestTuner_SuccessiveHalving(t *testing.T) {
+	tuner := Tuner{Space: SearchSpace{Hidden: [][]int{{2}, {3}}, Alphas: []float64{0.1, 0.5}}, MaxIterations: 27}
+	board, err := tuner.SuccessiveHalving(stepData(), 9, 3)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+
+	counts := map[int]int{}
+	for _, result := range board {
+		counts[result.Iterations]++
+	}
+
+	if len(board) != 9 || counts[27] != 1 || counts[9] != 2 || counts[3] != 6 || board[0].Iterations != 27 {
+		t.Errorf("Expected 1 trial with 27 iterations, 2 with 9 and 6 with 3 but got %v", counts)
+	}
+
+	if _, err := tuner.SuccessiveHalving(stepData(), 9, 1); err == nil {
+		t.Errorf("Expected an error for an eta of 1")
+	}
+}
+
+func TestLeaderboard_Write(t *testing.T) {
+	board := Leaderboard{
+		{Trial: Trial{Hidden: []int{4, 2}, Activation: "sigmoid", Alpha: 0.1}, Loss: 0.01, Iterations: 10},
+		{Trial: Trial{Activation: "sigmoid", Alpha: 0.5}, Err: errTest},
+	}
+
+	if text := board.String(); !strings.Contains(text, "rank") || !strings.Contains(text, "failed") {
+		t.Errorf("Unexpected leaderboard %s", text)
+	}
+
+	var buf bytes.Buffer
+	if err := board.WriteCSV(&buf); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+
+	if len(rows) != 3 || rows[1][1] != "4 2" || rows[2][9] != errTest.Error() {
+		t.Errorf("Unexpected CSV %v", rows)
+	}
+
+	if _, err := (Leaderboard{board[1]}).Best(); err == nil {
+		t.Errorf("Expected no best trial when every trial failed")
+	}
+}